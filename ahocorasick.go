@@ -0,0 +1,125 @@
+package secret
+
+import "strings"
+
+// matcher finds every occurrence of a set of patterns in one pass over the text (Aho–Corasick)
+type matcher struct {
+	next    []map[byte]int32
+	fail    []int32
+	longest []int
+}
+
+func newMatcher(patterns []string) *matcher {
+	m := &matcher{next: []map[byte]int32{{}}, fail: []int32{0}, longest: []int{0}}
+	for _, pattern := range patterns {
+		node := int32(0)
+		for i := 0; i < len(pattern); i++ {
+			child, ok := m.next[node][pattern[i]]
+			if !ok {
+				child = int32(len(m.next))
+				m.next = append(m.next, map[byte]int32{})
+				m.fail = append(m.fail, 0)
+				m.longest = append(m.longest, 0)
+				m.next[node][pattern[i]] = child
+			}
+
+			node = child
+		}
+
+		if len(pattern) > m.longest[node] {
+			m.longest[node] = len(pattern)
+		}
+	}
+
+	queue := make([]int32, 0, len(m.next))
+	for _, child := range m.next[0] {
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for c, child := range m.next[node] {
+			fail := m.fail[node]
+			for fail != 0 {
+				if _, ok := m.next[fail][c]; ok {
+					break
+				}
+
+				fail = m.fail[fail]
+			}
+
+			if target, ok := m.next[fail][c]; ok && target != child {
+				m.fail[child] = target
+			}
+
+			if m.longest[m.fail[child]] > m.longest[child] {
+				m.longest[child] = m.longest[m.fail[child]]
+			}
+
+			queue = append(queue, child)
+		}
+	}
+
+	return m
+}
+
+func (m *matcher) step(node int32, c byte) int32 {
+	for {
+		if child, ok := m.next[node][c]; ok {
+			return child
+		}
+
+		if node == 0 {
+			return 0
+		}
+
+		node = m.fail[node]
+	}
+}
+
+// replace returns s with every pattern occurrence replaced by replacement,
+// overlapping occurrences are replaced as one
+func (m *matcher) replace(s string, replacement string) string {
+	var out strings.Builder
+	node := int32(0)
+	written, spanStart, spanEnd := 0, -1, -1
+	for i := 0; i < len(s); i++ {
+		node = m.step(node, s[i])
+		length := m.longest[node]
+		if length == 0 {
+			continue
+		}
+
+		start := i + 1 - length
+		if start < written {
+			start = written
+		}
+
+		if spanEnd >= 0 && start <= spanEnd {
+			if start < spanStart {
+				spanStart = start
+			}
+
+			spanEnd = i + 1
+			continue
+		}
+
+		if spanEnd >= 0 {
+			out.WriteString(s[written:spanStart])
+			out.WriteString(replacement)
+			written = spanEnd
+		}
+
+		spanStart, spanEnd = start, i+1
+	}
+
+	if spanEnd < 0 {
+		return s
+	}
+
+	out.WriteString(s[written:spanStart])
+	out.WriteString(replacement)
+	out.WriteString(s[spanEnd:])
+	return out.String()
+}
@@ -0,0 +1,117 @@
+package secret
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Deprecation describes a deprecated key found in a secret document
+type Deprecation struct {
+	File        string
+	Key         string
+	Replacement string
+}
+
+func (d Deprecation) String() string {
+	return fmt.Sprintf("%s: %s is deprecated, use %s", d.File, d.Key, d.Replacement)
+}
+
+// aliasedTypes caches whether a type has `alias` tags anywhere within it
+var aliasedTypes sync.Map
+
+// hasAliases reports whether t or a type reachable from it has fields tagged `alias:"..."`,
+// only the final result is cached so concurrent callers never see a partial one
+func hasAliases(t reflect.Type) bool {
+	if cached, ok := aliasedTypes.Load(t); ok {
+		return cached.(bool)
+	}
+
+	found := typeHasAliases(t, make(map[reflect.Type]bool))
+	aliasedTypes.Store(t, found)
+	return found
+}
+
+// typeHasAliases walks t, visited breaks the cycles of recursive types
+func typeHasAliases(t reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[t] {
+		return false
+	}
+
+	visited[t] = true
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return typeHasAliases(t.Elem(), visited)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if _, tagged := t.Field(i).Tag.Lookup("alias"); tagged || typeHasAliases(t.Field(i).Type, visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// applyAliases renames the historical spellings of fields tagged `alias:"a,b"` in the parsed
+// document node to the field json name, reporting each through deprecated. When both spellings
+// are present the json name wins
+func applyAliases(node interface{}, t reflect.Type, fieldPath string, deprecated func(key string, replacement string)) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			applyStructAliases(n, t, fieldPath, deprecated)
+		case reflect.Map:
+			for key, value := range n {
+				applyAliases(value, t.Elem(), joinFieldPath(fieldPath, key), deprecated)
+			}
+		}
+	case []interface{}:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, value := range n {
+				applyAliases(value, t.Elem(), fmt.Sprintf("%s[%d]", fieldPath, i), deprecated)
+			}
+		}
+	}
+}
+
+func applyStructAliases(n map[string]interface{}, t reflect.Type, fieldPath string, deprecated func(key string, replacement string)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			applyStructAliases(n, field.Type, fieldPath, deprecated)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name := fieldName(field)
+		if aliases, ok := field.Tag.Lookup("alias"); ok {
+			for _, alias := range strings.Split(aliases, ",") {
+				value, ok := n[alias]
+				if !ok || alias == name {
+					continue
+				}
+
+				delete(n, alias)
+				if _, exists := n[name]; !exists {
+					n[name] = value
+				}
+
+				deprecated(joinFieldPath(fieldPath, alias), joinFieldPath(fieldPath, name))
+			}
+		}
+
+		if value, ok := n[name]; ok {
+			applyAliases(value, field.Type, joinFieldPath(fieldPath, name), deprecated)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package secret
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabaseAdapterSpellings(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("database-current/secret.json", []byte(`{"writer": {"adapter": "mysql", "params": {"host": "db"}}}`))
+	mockFS.AddFile("database-legacy/secret.json", []byte(`{
+		"writer": {"Adapter": "mysql", "params": {"host": "db"}},
+		"reader": {"driver": "postgres", "params": {"host": "replica"}}
+	}`))
+	mockFS.AddFile("database-both/secret.json", []byte(`{"writer": {"adapter": "mysql", "driver": "postgres", "params": {"host": "db"}}}`))
+
+	var mu sync.Mutex
+	var deprecations []string
+	loader := helper.MockLoader(WithStrictDecoding(), WithDeprecationHook(func(d Deprecation) {
+		mu.Lock()
+		defer mu.Unlock()
+		deprecations = append(deprecations, d.String())
+	}))
+
+	db := &Database{}
+	assert.NoError(t, loader.Load("database", "current", db))
+	assert.Equal(t, "mysql", db.Writer.Adapter)
+	assert.Empty(t, deprecations)
+
+	db = &Database{}
+	assert.NoError(t, loader.Load("database", "legacy", db))
+	assert.Equal(t, "mysql", db.Writer.Adapter)
+	assert.Equal(t, "postgres", db.Reader.Adapter)
+	assert.Equal(t, []string{
+		"database-legacy/secret.json: writer.Adapter is deprecated, use writer.adapter",
+		"database-legacy/secret.json: reader.driver is deprecated, use reader.adapter",
+	}, deprecations)
+
+	db = &Database{}
+	assert.NoError(t, loader.Load("database", "both", db))
+	assert.Equal(t, "mysql", db.Writer.Adapter)
+	assert.Len(t, deprecations, 3)
+}
+
+func TestDatabaseMarshalsAdapterKey(t *testing.T) {
+	t.Parallel()
+	data, err := json.Marshal(CreateDatabaseSecret("marshal").Writer)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"adapter":"mysql"`)
+}
+
+type aliasedNode struct {
+	Children []aliasedNode `json:"children"`
+	Name     string        `json:"name" alias:"label"`
+}
+
+func TestHasAliasesConcurrentFirstUse(t *testing.T) {
+	t.Parallel()
+	var wg sync.WaitGroup
+	results := make([]bool, 8)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = hasAliases(reflect.TypeOf(aliasedNode{}))
+		}()
+	}
+
+	wg.Wait()
+	for _, found := range results {
+		assert.True(t, found)
+	}
+}
@@ -0,0 +1,165 @@
+package secret
+
+import (
+	"path"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Cache keeps loaded secrets in process so hot paths don't reread and re-parse secret files,
+// every Load hands out a deep copy so callers can't mutate the cached value
+type Cache struct {
+	loader  *Loader
+	ttl     time.Duration
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[cacheKey]*cacheEntry
+}
+
+type cacheKey struct {
+	root   string
+	typ    string
+	name   string
+	target reflect.Type
+}
+
+type cacheEntry struct {
+	value    reflect.Value
+	modTimes map[string]time.Time
+	missing  []string
+	expires  time.Time
+}
+
+// NewCache creates a Cache in front of loader, entries expire after ttl, a ttl <= 0 never expires them
+func NewCache(loader *Loader, ttl time.Duration) *Cache {
+	if loader == nil {
+		loader = defaultLoader
+	}
+
+	return &Cache{
+		loader:  loader,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[cacheKey]*cacheEntry),
+	}
+}
+
+// Load loads the secret typ-name into secret, from the cache while the entry is fresh, none of
+// the files that contributed to it were modified and no overlay of the active profiles appeared
+func (c *Cache) Load(typ string, name string, secret Secret) error {
+	target := reflect.ValueOf(secret)
+	if target.Kind() != reflect.Ptr || target.Elem().Kind() != reflect.Struct {
+		return c.loader.Load(typ, name, secret)
+	}
+
+	key := cacheKey{root: c.loader.Root(), typ: typ, name: name, target: target.Elem().Type()}
+	if entry := c.lookup(key); entry != nil {
+		target.Elem().Set(deepCopy(entry.value))
+		return nil
+	}
+
+	fresh := reflect.New(key.target)
+	s := fresh.Interface().(Secret)
+	if err := c.loader.Load(typ, name, s); err != nil {
+		return err
+	}
+
+	entry := &cacheEntry{value: fresh.Elem(), modTimes: make(map[string]time.Time)}
+	files := []string{s.Path()}
+	if paths, ok := s.(interface{ Paths() []string }); ok && len(paths.Paths()) > 0 {
+		files = paths.Paths()
+	}
+
+	for _, file := range files {
+		if info, err := c.loader.fs.Stat(file); err == nil {
+			entry.modTimes[file] = info.ModTime()
+		}
+	}
+
+	// overlays of the active profiles that don't exist yet invalidate the entry once created
+	for _, file := range files {
+		if path.Base(file) != "secret.json" {
+			continue
+		}
+
+		for _, profile := range c.loader.Profiles() {
+			overlay := overlayPath(file, profile)
+			if _, ok := entry.modTimes[overlay]; ok {
+				continue
+			}
+
+			if _, err := c.loader.fs.Stat(overlay); err != nil {
+				entry.missing = append(entry.missing, overlay)
+			}
+		}
+	}
+
+	if c.ttl > 0 {
+		entry.expires = c.now().Add(c.ttl)
+	}
+
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.mu.Unlock()
+
+	target.Elem().Set(deepCopy(entry.value))
+	return nil
+}
+
+func (c *Cache) lookup(key cacheKey) *cacheEntry {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.remove(key, entry)
+		return nil
+	}
+
+	for file, modTime := range entry.modTimes {
+		if info, err := c.loader.fs.Stat(file); err != nil || !info.ModTime().Equal(modTime) {
+			c.remove(key, entry)
+			return nil
+		}
+	}
+
+	for _, file := range entry.missing {
+		if _, err := c.loader.fs.Stat(file); err == nil {
+			c.remove(key, entry)
+			return nil
+		}
+	}
+
+	return entry
+}
+
+func (c *Cache) remove(key cacheKey, entry *cacheEntry) {
+	c.mu.Lock()
+	if c.entries[key] == entry {
+		delete(c.entries, key)
+	}
+
+	c.mu.Unlock()
+}
+
+// Invalidate drops every cached value of the secret typ-name
+func (c *Cache) Invalidate(typ string, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if key.typ == typ && key.name == name {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// InvalidateAll drops every cached value
+func (c *Cache) InvalidateAll() {
+	c.mu.Lock()
+	c.entries = make(map[cacheKey]*cacheEntry)
+	c.mu.Unlock()
+}
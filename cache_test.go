@@ -0,0 +1,155 @@
+package secret
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingFileSystem counts reads so tests can tell cache hits from misses
+type countingFileSystem struct {
+	*MockFileSystem
+	reads int
+}
+
+func (fs *countingFileSystem) ReadFile(filename string) ([]byte, error) {
+	fs.reads++
+	return fs.MockFileSystem.ReadFile(filename)
+}
+
+func TestCacheHitReturnsCopy(t *testing.T) {
+	fs := &countingFileSystem{MockFileSystem: NewMockFileSystem()}
+	assert.NoError(t, fs.AddSecretFile("cassandra", "cache", CreateCassandraSecret("cache")))
+	cache := NewCache(NewLoader(WithFileSystem(fs)), 0)
+
+	first := &Cassandra{}
+	assert.NoError(t, cache.Load("cassandra", "cache", first))
+	first.Writer.Endpoints[0] = "mutated"
+	first.Writer.Keyspace = "mutated"
+
+	second := &Cassandra{}
+	assert.NoError(t, cache.Load("cassandra", "cache", second))
+	assert.Equal(t, 1, fs.reads)
+	assert.Equal(t, "cache", second.Name())
+	assert.Equal(t, "cassandra-cache/secret.json", second.Path())
+	assert.Equal(t, "localhost:9042", second.Writer.Endpoints[0])
+	assert.Equal(t, "test_keyspace", second.Writer.Keyspace)
+}
+
+func TestCacheTTL(t *testing.T) {
+	fs := &countingFileSystem{MockFileSystem: NewMockFileSystem()}
+	assert.NoError(t, fs.AddSecretFile("redis", "cache", CreateRedisSecret("cache")))
+	cache := NewCache(NewLoader(WithFileSystem(fs)), time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	assert.NoError(t, cache.Load("redis", "cache", &Redis{}))
+	assert.NoError(t, cache.Load("redis", "cache", &Redis{}))
+	assert.Equal(t, 1, fs.reads)
+
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, cache.Load("redis", "cache", &Redis{}))
+	assert.Equal(t, 2, fs.reads)
+}
+
+func TestCacheModTimeInvalidation(t *testing.T) {
+	fs := &countingFileSystem{MockFileSystem: NewMockFileSystem()}
+	assert.NoError(t, fs.AddSecretFile("redis", "cache", CreateRedisSecret("cache")))
+	cache := NewCache(NewLoader(WithFileSystem(fs)), 0)
+
+	assert.NoError(t, cache.Load("redis", "cache", &Redis{}))
+
+	updated := CreateRedisSecret("cache")
+	updated.Master.Host = "updated.localhost"
+	assert.NoError(t, fs.AddSecretFile("redis", "cache", updated))
+	fs.stats["redis-cache/secret.json"].(*mockFileInfo).modTime = time.Now().Add(time.Second)
+
+	redis := &Redis{}
+	assert.NoError(t, cache.Load("redis", "cache", redis))
+	assert.Equal(t, 2, fs.reads)
+	assert.Equal(t, "updated.localhost", redis.Master.Host)
+}
+
+func TestCacheFileResolverInvalidation(t *testing.T) {
+	fs := NewMockFileSystem()
+	fs.AddFile("redis-cache/secret.json", []byte(`{"master": {"host": "cache", "password": "${file:/run/secrets/redis}"}}`))
+	fs.AddFile("/run/secrets/redis", []byte("first-pw\n"))
+	cache := NewCache(NewLoader(WithFileSystem(fs), WithRegistry(nil)), 0)
+
+	redis := &Redis{}
+	assert.NoError(t, cache.Load("redis", "cache", redis))
+	assert.Equal(t, "first-pw", redis.Master.Password.Reveal())
+	assert.Contains(t, redis.Paths(), "/run/secrets/redis")
+
+	fs.AddFile("/run/secrets/redis", []byte("second-pw\n"))
+	fs.stats["/run/secrets/redis"].(*mockFileInfo).modTime = time.Now().Add(time.Second)
+
+	redis = &Redis{}
+	assert.NoError(t, cache.Load("redis", "cache", redis))
+	assert.Equal(t, "second-pw", redis.Master.Password.Reveal())
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	fs := &countingFileSystem{MockFileSystem: NewMockFileSystem()}
+	assert.NoError(t, fs.AddSecretFile("redis", "cache", CreateRedisSecret("cache")))
+	assert.NoError(t, fs.AddSecretFile("database", "cache", CreateDatabaseSecret("cache")))
+	cache := NewCache(NewLoader(WithFileSystem(fs)), 0)
+
+	assert.NoError(t, cache.Load("redis", "cache", &Redis{}))
+	assert.NoError(t, cache.Load("database", "cache", &Database{}))
+	cache.Invalidate("redis", "cache")
+	assert.NoError(t, cache.Load("redis", "cache", &Redis{}))
+	assert.NoError(t, cache.Load("database", "cache", &Database{}))
+	assert.Equal(t, 3, fs.reads)
+
+	cache.InvalidateAll()
+	assert.NoError(t, cache.Load("database", "cache", &Database{}))
+	assert.Equal(t, 4, fs.reads)
+
+	assert.Error(t, cache.Load("redis", "missing", &Redis{}))
+}
+
+type nestedSecret struct {
+	DefaultSecret
+	Rotated map[string]time.Time `json:"rotated"`
+	Extra   map[string]any       `json:"extra"`
+}
+
+func TestCacheCopiesMapsOfStructsAndInterfaces(t *testing.T) {
+	fs := &countingFileSystem{MockFileSystem: NewMockFileSystem()}
+	fs.AddFile("custom-nested/secret.json", []byte(`{
+		"rotated": {"key": "2024-01-02T03:04:05Z"},
+		"extra": {"n": {"k": "original"}, "list": ["a"]}
+	}`))
+	cache := NewCache(NewLoader(WithFileSystem(fs)), 0)
+
+	first := &nestedSecret{}
+	assert.NoError(t, cache.Load("custom", "nested", first))
+	first.Extra["n"].(map[string]any)["k"] = "mutated"
+	first.Extra["list"].([]any)[0] = "mutated"
+	first.Rotated["key"] = time.Time{}
+
+	second := &nestedSecret{}
+	assert.NoError(t, cache.Load("custom", "nested", second))
+	assert.Equal(t, 1, fs.reads)
+	assert.Equal(t, "original", second.Extra["n"].(map[string]any)["k"])
+	assert.Equal(t, "a", second.Extra["list"].([]any)[0])
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), second.Rotated["key"].UTC())
+}
+
+func TestCacheNewProfileOverlayInvalidation(t *testing.T) {
+	fs := &countingFileSystem{MockFileSystem: NewMockFileSystem()}
+	assert.NoError(t, fs.AddSecretFile("redis", "cache", CreateRedisSecret("cache")))
+	cache := NewCache(NewLoader(WithFileSystem(fs), WithProfile("prod")), 0)
+
+	assert.NoError(t, cache.Load("redis", "cache", &Redis{}))
+	assert.NoError(t, cache.Load("redis", "cache", &Redis{}))
+	assert.Equal(t, 1, fs.reads)
+
+	fs.AddFile("redis-cache/secret.prod.json", []byte(`{"master": {"host": "prod.localhost"}}`))
+	redis := &Redis{}
+	assert.NoError(t, cache.Load("redis", "cache", redis))
+	assert.Equal(t, 3, fs.reads)
+	assert.Equal(t, "prod.localhost", redis.Master.Host)
+}
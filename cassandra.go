@@ -1,15 +1,122 @@
 package secret
 
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+)
+
 type Cassandra struct {
 	DefaultSecret
-	Writer CassandraMeta `json:"writer"`
-	Reader CassandraMeta `json:"reader"`
+	Writer CassandraMeta `json:"writer" validate:"required"`
+	Reader CassandraMeta `json:"reader" inherit:"Writer"`
 }
 
+// CassandraMeta is one connection of a Cassandra. Port is the native protocol port of the
+// peers discovered from the endpoints and Consistency a gocql consistency name such as
+// LOCAL_QUORUM, case insensitive
 type CassandraMeta struct {
-	Endpoints []string `json:"endpoints"`
+	Endpoints []string `json:"endpoints" validate:"required"`
 	Keyspace  string   `json:"keyspace"`
 	Username  string   `json:"username"`
-	Password  string   `json:"password"`
-	CaPath    string   `json:"ca_path"`
+	// Password used to be a string, code reading it as one should call Reveal
+	Password Sensitive `json:"password"`
+	CaPath   string    `json:"ca_path" validate:"file"`
+	CertPath string    `json:"cert_path" validate:"file"`
+	KeyPath  string    `json:"key_path" validate:"file"`
+	// HostVerification checks the server certificate names the endpoint, otherwise only the
+	// chain is verified against CaPath
+	HostVerification bool     `json:"host_verification"`
+	LocalDC          string   `json:"local_dc"`
+	Port             uint     `json:"port" validate:"min=1,max=65535"`
+	ProtocolVersion  int      `json:"protocol_version" validate:"min=1,max=5"`
+	Consistency      string   `json:"consistency"`
+	ConnectTimeout   Duration `json:"connect_timeout" validate:"min=0"`
+	Timeout          Duration `json:"timeout" validate:"min=0"`
+}
+
+var cassandraConsistencies = []string{"ANY", "ONE", "TWO", "THREE", "QUORUM", "ALL", "LOCAL_QUORUM", "EACH_QUORUM", "LOCAL_ONE"}
+
+// Destroy clears the cassandra sensitive values, see the package level Destroy
+func (c *Cassandra) Destroy() {
+	Destroy(c)
+}
+
+// LogValue logs the cassandra with its passwords redacted
+func (c *Cassandra) LogValue() slog.Value {
+	return secretLogValue(reflect.ValueOf(c))
+}
+
+// ApplyDefaults lets a reader that omits the keyspace use the writer keyspace
+func (c *Cassandra) ApplyDefaults() {
+	if c.Reader.Keyspace == "" && !isZero(c.Reader) {
+		c.Reader.Keyspace = c.Writer.Keyspace
+	}
+}
+
+// TLSConfig returns the *tls.Config loading CaPath and the client certificate, or nil when
+// none of them is set
+func (m *CassandraMeta) TLSConfig() (*tls.Config, error) {
+	return m.TLSConfigFS(&RealFileSystem{})
+}
+
+// TLSConfigFS is TLSConfig reading the files from fs, for secrets loaded WithFileSystem
+func (m *CassandraMeta) TLSConfigFS(fs FileSystemInterface) (*tls.Config, error) {
+	if m.CaPath == "" && m.CertPath == "" && m.KeyPath == "" {
+		return nil, nil
+	}
+
+	return m.tls().ConfigFS(fs, "")
+}
+
+func (m *CassandraMeta) tls() *TLS {
+	t := &TLS{CA: m.CaPath, Cert: m.CertPath, Key: Sensitive(m.KeyPath), VerifyMode: VerifyCA}
+	if m.HostVerification {
+		t.VerifyMode = VerifyFull
+	}
+
+	return t
+}
+
+// Validate checks endpoints are host:port pairs, CaPath holds PEM certificates, the client
+// key matches its certificate and the consistency is known, the reader is only checked
+// when present
+func (c *Cassandra) Validate() error {
+	return c.ValidateFS(&RealFileSystem{})
+}
+
+// ValidateFS is Validate reading CaPath and the client certificate from fs
+func (c *Cassandra) ValidateFS(fs FileSystemInterface) error {
+	errs := &ValidationError{}
+	c.Writer.validate("writer", fs, errs)
+	if !isZero(c.Reader) {
+		c.Reader.validate("reader", fs, errs)
+	}
+
+	return errs.Err()
+}
+
+func (m *CassandraMeta) validate(prefix string, fs FileSystemInterface, errs *ValidationError) {
+	for i, endpoint := range m.Endpoints {
+		validateHostPort(fmt.Sprintf("%s.endpoints[%d]", prefix, i), endpoint, errs)
+	}
+
+	if m.CaPath != "" {
+		if err := checkPEMCertificates(fs, m.CaPath); err != nil {
+			errs.Add(prefix+".ca_path", "pem", "%v", err)
+		}
+	}
+
+	if m.CertPath != "" || m.KeyPath != "" {
+		if _, err := m.tls().certificate(fs); err != nil {
+			errs.Add(prefix+".cert_path", "pem", "%v", err)
+		}
+	}
+
+	if m.Consistency != "" && !slices.Contains(cassandraConsistencies, strings.ToUpper(m.Consistency)) {
+		errs.Add(prefix+".consistency", "consistency", "must be one of [%s], got %q", strings.Join(cassandraConsistencies, " "), m.Consistency)
+	}
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -21,20 +22,21 @@ func TestCassandraProfile(t *testing.T) {
 
 	testData := `{
   "writer": {
-    "endpoints": ["wh1", "wh2"],
+    "endpoints": ["wh1:9042", "wh2:9042"],
     "username": "wu",
     "password": "wp",
-    "ca_path": "wcp"
+    "ca_path": "cassandra-test/certs/ca.pem"
   },
   "reader": {
-    "endpoints": ["rh1"],
+    "endpoints": ["rh1:9042"],
     "username": "ru",
     "password": "rp",
-    "ca_path": "rcp"
+    "ca_path": "cassandra-test/certs/ca.pem"
   }
 }`
 
 	os.WriteFile(secretDir, []byte(testData), fs.ModePerm)
+	CreateTestCertificates(t, path.Join(testDir, "certs"))
 
 	c := &Cassandra{}
 	err := Load("cassandra", "test", c)
@@ -44,13 +46,77 @@ func TestCassandraProfile(t *testing.T) {
 	assert.NotEmpty(t, c.Path())
 
 	assert.Equal(t, 2, len(c.Writer.Endpoints))
-	assert.Equal(t, "wh2", c.Writer.Endpoints[1])
+	assert.Equal(t, "wh2:9042", c.Writer.Endpoints[1])
 	assert.Equal(t, "wu", c.Writer.Username)
-	assert.Equal(t, "wp", c.Writer.Password)
-	assert.Equal(t, "wcp", c.Writer.CaPath)
+	assert.Equal(t, "wp", c.Writer.Password.Reveal())
+	assert.Equal(t, "cassandra-test/certs/ca.pem", c.Writer.CaPath)
 
 	assert.Equal(t, 1, len(c.Reader.Endpoints))
 	assert.Equal(t, "ru", c.Reader.Username)
-	assert.Equal(t, "rp", c.Reader.Password)
-	assert.Equal(t, "rcp", c.Reader.CaPath)
+	assert.Equal(t, "rp", c.Reader.Password.Reveal())
+	assert.Equal(t, "cassandra-test/certs/ca.pem", c.Reader.CaPath)
+}
+
+func TestCassandraConnectionSettings(t *testing.T) {
+	t.Parallel()
+	certs := CreateTestCertificates(t, t.TempDir())
+	helper := NewTestHelper()
+	helper.GetMockFileSystem().AddFile(certs.CAPath, certs.CAPEM)
+	helper.GetMockFileSystem().AddFile(certs.CertPath, certs.CertPEM)
+	helper.GetMockFileSystem().AddFile(certs.KeyPath, certs.KeyPEM)
+	helper.GetMockFileSystem().AddFile("cassandra-settings/secret.json", []byte(`{
+		"writer": {
+			"endpoints": ["localhost:9042"], "ca_path": "`+certs.CAPath+`", "cert_path": "`+certs.CertPath+`", "key_path": "`+certs.KeyPath+`",
+			"local_dc": "dc1", "port": 9142, "protocol_version": 4, "consistency": "local_quorum", "connect_timeout": "2s", "timeout": 5
+		}
+	}`))
+	helper.GetMockFileSystem().AddFile("cassandra-invalid/secret.json", []byte(`{
+		"writer": {"endpoints": ["localhost:9042"], "port": 70000, "protocol_version": 6, "consistency": "most"}
+	}`))
+
+	c := &Cassandra{}
+	assert.NoError(t, helper.MockLoader().Load("cassandra", "settings", c))
+	assert.Equal(t, "dc1", c.Reader.LocalDC)
+	assert.Equal(t, uint(9142), c.Writer.Port)
+	assert.Equal(t, 4, c.Writer.ProtocolVersion)
+	assert.Equal(t, 2*time.Second, c.Writer.ConnectTimeout.Duration())
+	assert.Equal(t, 5*time.Second, c.Writer.Timeout.Duration())
+
+	config, err := c.Writer.TLSConfig()
+	assert.NoError(t, err)
+	assert.Len(t, config.Certificates, 1)
+	assert.NoError(t, handshake(t, certs, config))
+
+	err = helper.MockLoader().Load("cassandra", "invalid", &Cassandra{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "writer.port must be at most 65535")
+	assert.Contains(t, err.Error(), "writer.protocol_version must be at most 5")
+
+	c.Writer.Consistency = "most"
+	assert.Contains(t, c.Validate().Error(), `writer.consistency must be one of [ANY ONE TWO THREE QUORUM ALL LOCAL_QUORUM EACH_QUORUM LOCAL_ONE], got "most"`)
+}
+
+func TestCassandraTLSConfig(t *testing.T) {
+	t.Parallel()
+	certs := CreateTestCertificates(t, t.TempDir())
+	other := CreateTestCertificates(t, t.TempDir())
+
+	config, err := (&CassandraMeta{}).TLSConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+
+	meta := &CassandraMeta{Endpoints: []string{"localhost:9042"}, CaPath: certs.CAPath, CertPath: certs.CertPath, KeyPath: certs.KeyPath}
+	config, err = meta.TLSConfig()
+	assert.NoError(t, err)
+	assert.True(t, config.InsecureSkipVerify)
+	assert.NotNil(t, config.VerifyConnection)
+
+	meta.HostVerification = true
+	config, err = meta.TLSConfig()
+	assert.NoError(t, err)
+	assert.False(t, config.InsecureSkipVerify)
+
+	meta.KeyPath = other.KeyPath
+	c := &Cassandra{Writer: *meta}
+	assert.Contains(t, c.Validate().Error(), "writer.cert_path cert and key: tls: private key does not match public key")
 }
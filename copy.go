@@ -0,0 +1,102 @@
+package secret
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// deepCopy returns an addressable copy of v sharing no slices, maps or pointers with it
+func deepCopy(v reflect.Value) reflect.Value {
+	dst := reflect.New(v.Type()).Elem()
+	copyValue(dst, v)
+	return dst
+}
+
+func copyValue(dst reflect.Value, src reflect.Value) {
+	dst, src = accessible(dst), addressable(accessible(src))
+	if src.Type() == secretBytesType {
+		dst.Set(reflect.ValueOf(src.Interface().(SecretBytes).clone()))
+		return
+	}
+
+	if src.Type() == sensitiveType {
+		dst.SetString(string(Sensitive(src.String()).clone()))
+		return
+	}
+
+	switch src.Kind() {
+	case reflect.Struct:
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			copyValue(dst.Field(i), src.Field(i))
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			copyValue(s.Index(i), src.Index(i))
+		}
+
+		dst.Set(s)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+
+		dst.Set(m)
+	case reflect.Ptr:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+
+		p := reflect.New(src.Type().Elem())
+		copyValue(p.Elem(), src.Elem())
+		dst.Set(p)
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return
+		}
+
+		dst.Set(deepCopy(src.Elem()))
+	default:
+		dst.Set(src)
+	}
+}
+
+// addressable returns v, or an addressable copy of v when it is a map value or the dynamic
+// value of an interface, so the unexported fields of structs held there can be reached
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+
+	tmp := reflect.New(v.Type()).Elem()
+	tmp.Set(v)
+	return tmp
+}
+
+// accessible lifts the read-only flag reflect puts on values reached through unexported fields
+func accessible(v reflect.Value) reflect.Value {
+	if v.CanInterface() || !v.CanAddr() {
+		return v
+	}
+
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
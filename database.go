@@ -1,19 +1,258 @@
 package secret
 
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Database is a database-<name>/secret.json secret, schema/database.schema.json
+// describes its document:
+//
+//	{
+//	  "writer": {"adapter": "mysql", "params": {"host": "db", "port": 3306, ...}},
+//	  "reader": {"adapter": "mysql", "params": {...}},
+//	  "readers": [{"adapter": "mysql", "weight": 2, "params": {...}}, ...]
+//	}
 type Database struct {
 	DefaultSecret
-	Writer DatabaseMeta `json:"writer"`
-	Reader DatabaseMeta `json:"reader"`
+	Writer  DatabaseMeta   `json:"writer" validate:"required"`
+	Reader  DatabaseMeta   `json:"reader" inherit:"Writer"`
+	Readers []DatabaseMeta `json:"readers"`
 }
 
+// DatabaseMeta is one connection of a Database, Adapter also accepts the deprecated
+// "Adapter" and "driver" keys. The postgres host may be the directory of its unix socket
 type DatabaseMeta struct {
-	Adapter string
-	Params  struct {
+	Adapter string `json:"adapter" alias:"Adapter,driver" validate:"required"`
+	// Weight is the share of reads of a reader under the Weighted strategy
+	Weight uint `json:"weight"`
+	Params struct {
 		Charset  string `json:"charset"`
 		Host     string `json:"host"`
-		Port     uint   `json:"port"`
+		Port     uint   `json:"port" validate:"min=1,max=65535"`
 		DBName   string `json:"dbname"`
 		Username string `json:"username"`
-		Password string `json:"password"`
-	} `json:"params"`
+		// Password used to be a string, code reading it as one should call Reveal
+		Password Sensitive `json:"password"`
+
+		MaxOpenConns    int      `json:"max_open_conns" validate:"min=0"`
+		MaxIdleConns    int      `json:"max_idle_conns" validate:"min=0"`
+		ConnMaxLifetime Duration `json:"conn_max_lifetime" validate:"min=0"`
+		ConnMaxIdleTime Duration `json:"conn_max_idle_time" validate:"min=0"`
+		ConnectTimeout  Duration `json:"connect_timeout" validate:"min=0"`
+		ReadTimeout     Duration `json:"read_timeout" validate:"min=0"`
+		WriteTimeout    Duration `json:"write_timeout" validate:"min=0"`
+		TLSMode         string   `json:"tls_mode" validate:"oneof=disable prefer require verify-ca verify-full"`
+		TimeZone        string   `json:"time_zone"`
+		// TLS is applied by TLSConfig, the DSN carries only postgres file paths and a mysql
+		// config registered with mysql.RegisterTLSConfig under driver_params.tls
+		TLS TLS `json:"tls"`
+		// DriverParams are passed to the driver as is and override the generated parameters
+		DriverParams map[string]string `json:"driver_params"`
+	} `json:"params" validate:"required"`
+}
+
+// TLS modes of DatabaseMeta, named after the postgres sslmode values and mapped to the
+// closest setting of the other adapters
+const (
+	TLSModeDisable    = "disable"
+	TLSModePrefer     = "prefer"
+	TLSModeRequire    = "require"
+	TLSModeVerifyCA   = "verify-ca"
+	TLSModeVerifyFull = "verify-full"
+)
+
+// databaseAdapter describes a known Database adapter and its default port and charset,
+// file adapters address their database with params.dbname instead of a host and port and
+// socket adapters also accept the absolute path of a unix socket directory as host
+type databaseAdapter struct {
+	port    uint
+	charset string
+	file    bool
+	socket  bool
+	builder DSNBuilder
+}
+
+var databaseAdaptersMu sync.RWMutex
+
+var databaseAdapters = func() map[string]*databaseAdapter {
+	mysql := &databaseAdapter{port: 3306, charset: "utf8mb4", builder: mysqlDSN{}}
+	postgres := &databaseAdapter{port: 5432, socket: true, builder: postgresDSN{}}
+	sqlserver := &databaseAdapter{port: 1433, builder: sqlserverDSN{}}
+	sqlite := &databaseAdapter{file: true, builder: sqliteDSN{}}
+	return map[string]*databaseAdapter{
+		"mysql":      mysql,
+		"postgres":   postgres,
+		"postgresql": postgres,
+		"sqlserver":  sqlserver,
+		"mssql":      sqlserver,
+		"sqlite":     sqlite,
+		"sqlite3":    sqlite,
+	}
+}()
+
+// RegisterDatabaseAdapter marks adapter as a known Database adapter
+func RegisterDatabaseAdapter(adapter string) {
+	databaseAdaptersMu.Lock()
+	defer databaseAdaptersMu.Unlock()
+	if databaseAdapters[adapter] == nil {
+		databaseAdapters[adapter] = &databaseAdapter{}
+	}
+}
+
+func lookupDatabaseAdapter(adapter string) *databaseAdapter {
+	databaseAdaptersMu.RLock()
+	defer databaseAdaptersMu.RUnlock()
+	return databaseAdapters[adapter]
+}
+
+func isDatabaseAdapter(adapter string) bool {
+	return lookupDatabaseAdapter(adapter) != nil
+}
+
+// ApplyDefaults sets the port and charset of the writer and readers to the adapter default
+// when omitted, only mysql has a default charset, utf8mb4
+func (d *Database) ApplyDefaults() {
+	metas := []*DatabaseMeta{&d.Writer, &d.Reader}
+	for i := range d.Readers {
+		metas = append(metas, &d.Readers[i])
+	}
+
+	for _, meta := range metas {
+		adapter := lookupDatabaseAdapter(meta.Adapter)
+		if adapter == nil || isZero(*meta) {
+			continue
+		}
+
+		if meta.Params.Port == 0 {
+			meta.Params.Port = adapter.port
+		}
+
+		if meta.Params.Charset == "" {
+			meta.Params.Charset = adapter.charset
+		}
+	}
+}
+
+// TLSConfig returns the *tls.Config of the connection, or nil when the TLS mode is disable or
+// neither a TLS mode nor TLS material is set. Without a verify mode, require and prefer skip
+// verification and verify-ca verifies the chain only, like the postgres sslmode
+func (m *DatabaseMeta) TLSConfig() (*tls.Config, error) {
+	return m.TLSConfigFS(&RealFileSystem{})
+}
+
+// TLSConfigFS is TLSConfig reading the TLS files from fs, for secrets loaded WithFileSystem
+func (m *DatabaseMeta) TLSConfigFS(fs FileSystemInterface) (*tls.Config, error) {
+	if m.Params.TLSMode == TLSModeDisable || m.Params.TLSMode == "" && isZero(m.Params.TLS) {
+		return nil, nil
+	}
+
+	t := m.Params.TLS
+	if t.VerifyMode == "" {
+		switch m.Params.TLSMode {
+		case TLSModePrefer, TLSModeRequire:
+			t.VerifyMode = VerifyNone
+		case TLSModeVerifyCA:
+			t.VerifyMode = VerifyCA
+		}
+	}
+
+	return t.ConfigFS(fs, strings.Trim(m.Params.Host, "[]"))
+}
+
+// Replicas returns the connections to read from: the readers array, preceded by the reader
+// when the document sets it, else the reader, which inherits the writer when omitted
+func (d *Database) Replicas() []DatabaseMeta {
+	var replicas []DatabaseMeta
+	if !isZero(d.Reader) && (len(d.Readers) == 0 || !d.Inherited("reader")) {
+		replicas = append(replicas, d.Reader)
+	}
+
+	replicas = append(replicas, d.Readers...)
+	if len(replicas) == 0 {
+		replicas = append(replicas, d.Writer)
+	}
+
+	return replicas
+}
+
+// ReaderSelector returns a selector over the Replicas weighted by their weight, RoundRobin
+// is used when strategy is nil
+func (d *Database) ReaderSelector(strategy Strategy, opts ...SelectorOption[DatabaseMeta]) *Selector[DatabaseMeta] {
+	weight := WithWeight(func(meta DatabaseMeta) uint { return meta.Weight })
+	return NewSelector(d.Replicas(), strategy, append([]SelectorOption[DatabaseMeta]{weight}, opts...)...)
+}
+
+// Destroy clears the database sensitive values, see the package level Destroy
+func (d *Database) Destroy() {
+	Destroy(d)
+}
+
+// LogValue logs the database with its passwords redacted
+func (d *Database) LogValue() slog.Value {
+	return secretLogValue(reflect.ValueOf(d))
+}
+
+// Validate checks the writer and, when present, the readers use a known adapter and set a host
+// and valid port, or a dbname for file adapters such as sqlite
+func (d *Database) Validate() error {
+	return d.ValidateFS(&RealFileSystem{})
+}
+
+// ValidateFS is Validate reading the TLS files from fs
+func (d *Database) ValidateFS(fs FileSystemInterface) error {
+	errs := &ValidationError{}
+	d.Writer.validate("writer", fs, errs)
+	if !isZero(d.Reader) {
+		d.Reader.validate("reader", fs, errs)
+	}
+
+	for i := range d.Readers {
+		d.Readers[i].validate(fmt.Sprintf("readers[%d]", i), fs, errs)
+	}
+
+	return errs.Err()
+}
+
+func (m *DatabaseMeta) validate(prefix string, fs FileSystemInterface, errs *ValidationError) {
+	if m.Params.TimeZone != "" {
+		if _, err := time.LoadLocation(m.Params.TimeZone); err != nil {
+			errs.Add(prefix+".params.time_zone", "time_zone", "must be a known time zone: %v", err)
+		}
+	}
+
+	if !isZero(m.Params.TLS) {
+		if _, err := m.Params.TLS.ConfigFS(fs, ""); err != nil {
+			errs.Add(prefix+".params.tls", "tls", "%v", err)
+		}
+	}
+
+	adapter := lookupDatabaseAdapter(m.Adapter)
+	if adapter == nil {
+		errs.Add(prefix+".adapter", "adapter", "must be a known adapter, got %q", m.Adapter)
+	} else if adapter.file {
+		if m.Params.DBName == "" {
+			errs.Add(prefix+".params.dbname", "required", "is required by the %s adapter", m.Adapter)
+		}
+
+		return
+	}
+
+	switch host := m.Params.Host; {
+	case host == "":
+		errs.Add(prefix+".params.host", "required", "is required")
+	case adapter != nil && adapter.socket && path.IsAbs(host):
+	case !isHostname(host):
+		errs.Add(prefix+".params.host", "hostname", "must be a valid hostname, got %q", host)
+	}
+
+	if m.Params.Port == 0 || m.Params.Port > 65535 {
+		errs.Add(prefix+".params.port", "port", "must be within 1-65535, got %d", m.Params.Port)
+	}
 }
@@ -59,7 +59,7 @@ func TestDatabaseProfile(t *testing.T) {
 	assert.Equal(t, uint(3306), db.Writer.Params.Port)
 	assert.Equal(t, "test_db", db.Writer.Params.DBName)
 	assert.Equal(t, "test_user", db.Writer.Params.Username)
-	assert.Equal(t, "test_password", db.Writer.Params.Password)
+	assert.Equal(t, "test_password", db.Writer.Params.Password.Reveal())
 
 	assert.Equal(t, "mysql", db.Reader.Adapter)
 	assert.Equal(t, "utf8mb4", db.Reader.Params.Charset)
@@ -67,5 +67,5 @@ func TestDatabaseProfile(t *testing.T) {
 	assert.Equal(t, uint(3306), db.Reader.Params.Port)
 	assert.Equal(t, "test_db_readonly", db.Reader.Params.DBName)
 	assert.Equal(t, "readonly_user", db.Reader.Params.Username)
-	assert.Equal(t, "readonly_password", db.Reader.Params.Password)
+	assert.Equal(t, "readonly_password", db.Reader.Params.Password.Reveal())
 }
@@ -0,0 +1,169 @@
+package secret
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// Defaulter is implemented by secrets whose defaults depend on other fields,
+// Load calls ApplyDefaults after the `default` struct tags were applied
+type Defaulter interface {
+	ApplyDefaults()
+}
+
+// applyDefaults sets zero fields tagged `default:"..."` to the tag value.
+// Slices take a comma separated list and durations use time.ParseDuration.
+// Defaults are only applied inside sections present in the document,
+// so optional sections like Reader stay absent, and to each element of struct slices
+func applyDefaults(v reflect.Value) error {
+	errs := &ValidationError{}
+	applyStructDefaults(v, "", errs)
+	if err := errs.Err(); err != nil {
+		return err
+	}
+
+	if defaulter, ok := v.Addr().Interface().(Defaulter); ok {
+		defaulter.ApplyDefaults()
+	}
+
+	return nil
+}
+
+func applyStructDefaults(v reflect.Value, prefix string, errs *ValidationError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldValue := v.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			applyStructDefaults(fieldValue, prefix, errs)
+			continue
+		}
+
+		fieldPath := joinFieldPath(prefix, fieldName(field))
+		if tag, ok := field.Tag.Lookup("default"); ok && fieldValue.IsZero() {
+			if err := setDefault(fieldValue, tag); err != nil {
+				errs.Add(fieldPath, "default", "has invalid default %q: %v", tag, err)
+			}
+
+			continue
+		}
+
+		switch {
+		case fieldValue.Kind() == reflect.Struct && !fieldValue.IsZero():
+			applyStructDefaults(fieldValue, fieldPath, errs)
+		case fieldValue.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+			for j := 0; j < fieldValue.Len(); j++ {
+				applyStructDefaults(fieldValue.Index(j), fmt.Sprintf("%s[%d]", fieldPath, j), errs)
+			}
+		}
+	}
+}
+
+var (
+	durationType       = reflect.TypeOf(time.Duration(0))
+	secretDurationType = reflect.TypeOf(Duration(0))
+)
+
+func setDefault(v reflect.Value, value string) error {
+	if v.Type() == durationType || v.Type() == secretDurationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice of %s", v.Type().Elem())
+		}
+
+		parts := strings.Split(value, ",")
+		s := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			s.Index(i).SetString(strings.TrimSpace(part))
+		}
+
+		v.Set(s)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// applyInheritance copies into every zero field tagged `inherit:"Field"` the value
+// of its sibling Field, like a Reader omitted from the document inheriting the Writer,
+// and returns the json names of the inherited fields
+func applyInheritance(v reflect.Value) []string {
+	var inherited []string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		from, ok := field.Tag.Lookup("inherit")
+		if !ok || !field.IsExported() || !v.Field(i).IsZero() {
+			continue
+		}
+
+		source := v.FieldByName(from)
+		if !source.IsValid() || source.Type() != field.Type || source.IsZero() {
+			continue
+		}
+
+		v.Field(i).Set(deepCopy(source))
+		inherited = append(inherited, fieldName(field))
+	}
+
+	return inherited
+}
+
+func setInherited(defaultSecretField reflect.Value, inherited []string) {
+	inheritedField := defaultSecretField.FieldByName("_Inherited")
+	if !inheritedField.IsValid() {
+		return
+	}
+
+	v := reflect.NewAt(inheritedField.Type(), unsafe.Pointer(inheritedField.UnsafeAddr()))
+	v.Elem().Set(reflect.ValueOf(inherited))
+}
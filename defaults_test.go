@@ -0,0 +1,136 @@
+package secret
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type defaultedSecret struct {
+	DefaultSecret
+	Label    string        `json:"label" default:"fallback"`
+	Enabled  bool          `json:"enabled" default:"true"`
+	Retries  int           `json:"retries" default:"3"`
+	Ratio    float64       `json:"ratio" default:"0.5"`
+	Timeout  time.Duration `json:"timeout" default:"5s"`
+	Zones    []string      `json:"zones" default:"a, b"`
+	Optional struct {
+		Port uint   `json:"port" default:"80"`
+		Host string `json:"host"`
+	} `json:"optional"`
+}
+
+type invalidDefaultSecret struct {
+	DefaultSecret
+	Retries int `json:"retries" default:"many"`
+}
+
+func TestDefaultTags(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("custom-absent/secret.json", []byte(`{"label": "explicit"}`))
+	mockFS.AddFile("custom-present/secret.json", []byte(`{"optional": {"host": "h"}}`))
+	mockFS.AddFile("custom-invalid/secret.json", []byte(`{}`))
+
+	s := &defaultedSecret{}
+	assert.NoError(t, helper.MockLoader().Load("custom", "absent", s))
+	assert.Equal(t, "explicit", s.Label)
+	assert.True(t, s.Enabled)
+	assert.Equal(t, 3, s.Retries)
+	assert.Equal(t, 0.5, s.Ratio)
+	assert.Equal(t, 5*time.Second, s.Timeout)
+	assert.Equal(t, []string{"a", "b"}, s.Zones)
+	assert.Equal(t, uint(0), s.Optional.Port)
+
+	s = &defaultedSecret{}
+	assert.NoError(t, helper.MockLoader().Load("custom", "present", s))
+	assert.Equal(t, "fallback", s.Label)
+	assert.Equal(t, uint(80), s.Optional.Port)
+
+	err := helper.MockLoader().Load("custom", "invalid", &invalidDefaultSecret{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `retries has invalid default "many"`)
+}
+
+func TestDatabaseDefaultsAndInheritance(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("database-inherit/secret.json", []byte(`{
+		"writer": {"adapter": "postgres", "params": {"host": "db", "dbname": "app", "password": "pw"}}
+	}`))
+	mockFS.AddFile("database-explicit/secret.json", []byte(`{
+		"writer": {"adapter": "mysql", "params": {"host": "db"}},
+		"reader": {"adapter": "mysql", "params": {"host": "replica", "charset": "latin1"}}
+	}`))
+
+	db := &Database{}
+	assert.NoError(t, helper.MockLoader().Load("database", "inherit", db))
+	assert.Equal(t, uint(5432), db.Writer.Params.Port)
+	assert.Empty(t, db.Writer.Params.Charset)
+	assert.True(t, db.Inherited("reader"))
+	assert.False(t, db.Inherited("writer"))
+	assert.Equal(t, db.Writer, db.Reader)
+
+	db = &Database{}
+	assert.NoError(t, helper.MockLoader().Load("database", "explicit", db))
+	assert.False(t, db.Inherited("reader"))
+	assert.Equal(t, uint(3306), db.Reader.Params.Port)
+	assert.Equal(t, "utf8mb4", db.Writer.Params.Charset)
+	assert.Equal(t, "latin1", db.Reader.Params.Charset)
+	assert.Equal(t, "replica", db.Reader.Params.Host)
+}
+
+func TestDatabaseReadersDefaults(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	helper.GetMockFileSystem().AddFile("database-readers/secret.json", []byte(`{
+		"writer": {"adapter": "mysql", "params": {"host": "db"}},
+		"readers": [
+			{"adapter": "mysql", "params": {"host": "replica-1"}},
+			{"adapter": "mysql", "params": {"host": "replica-2", "charset": "latin1"}}
+		]
+	}`))
+
+	db := &Database{}
+	assert.NoError(t, helper.MockLoader().Load("database", "readers", db))
+	assert.Equal(t, "utf8mb4", db.Readers[0].Params.Charset)
+	assert.Equal(t, uint(3306), db.Readers[0].Params.Port)
+	assert.Equal(t, "latin1", db.Readers[1].Params.Charset)
+}
+
+func TestRedisAndCassandraInheritance(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("redis-inherit/secret.json", []byte(`{"master": {"host": "m"}}`))
+	mockFS.AddFile("cassandra-inherit/secret.json", []byte(`{
+		"writer": {"endpoints": ["w:9042"], "keyspace": "ks"},
+		"reader": {"endpoints": ["r:9042"]}
+	}`))
+
+	redis := &Redis{}
+	assert.NoError(t, helper.MockLoader().Load("redis", "inherit", redis))
+	assert.Equal(t, uint(6379), redis.Master.Port)
+	assert.True(t, redis.Inherited("slave"))
+	assert.Equal(t, redis.Master, redis.Slave)
+
+	c := &Cassandra{}
+	assert.NoError(t, helper.MockLoader().Load("cassandra", "inherit", c))
+	assert.False(t, c.Inherited("reader"))
+	assert.Equal(t, "ks", c.Reader.Keyspace)
+	assert.Equal(t, []string{"r:9042"}, c.Reader.Endpoints)
+}
+
+func TestInheritedValueIsCopied(t *testing.T) {
+	t.Parallel()
+	c := CreateCassandraSecret("copy")
+	c.Reader = CassandraMeta{}
+	assert.Equal(t, []string{"reader"}, applyInheritance(reflect.ValueOf(c).Elem()))
+
+	c.Reader.Endpoints[0] = "changed"
+	assert.Equal(t, "localhost:9042", c.Writer.Endpoints[0])
+}
@@ -0,0 +1,396 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DependencyGraph records which secrets a secret document references through $ref,
+// secrets are identified as typ-name like their directory
+type DependencyGraph struct {
+	edges map[string][]string
+}
+
+func newDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{edges: make(map[string][]string)}
+}
+
+func (g *DependencyGraph) add(from string, to string) {
+	for _, existing := range g.edges[from] {
+		if existing == to {
+			return
+		}
+	}
+
+	g.edges[from] = append(g.edges[from], to)
+}
+
+// Dependencies returns the secrets id references directly
+func (g *DependencyGraph) Dependencies(id string) []string {
+	return append([]string(nil), g.edges[id]...)
+}
+
+// Secrets returns every secret with references, sorted
+func (g *DependencyGraph) Secrets() []string {
+	ids := make([]string, 0, len(g.edges))
+	for id := range g.edges {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+func (g *DependencyGraph) String() string {
+	var lines []string
+	for _, id := range g.Secrets() {
+		lines = append(lines, fmt.Sprintf("%s -> %s", id, strings.Join(g.edges[id], ", ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// documentSet reads the secret documents involved in one load, merging profile overlays
+// and resolving placeholders and $ref references across secrets of the same root
+type documentSet struct {
+	loader   *Loader
+	root     string
+	profiles []string
+	trees    map[string]interface{}
+	files    []string
+	buffers  [][]byte
+	graph    *DependencyGraph
+}
+
+func newDocumentSet(loader *Loader, root string) *documentSet {
+	return &documentSet{
+		loader:   loader,
+		root:     root,
+		profiles: loader.Profiles(),
+		trees:    make(map[string]interface{}),
+		graph:    newDependencyGraph(),
+	}
+}
+
+func (s *documentSet) path(id string) string {
+	return path.Join(s.root, id, "secret.json")
+}
+
+// overlays returns the existing secret.<profile>.json files of secret id in profile order
+func (s *documentSet) overlays(id string) []string {
+	var overlays []string
+	for _, profile := range s.profiles {
+		overlay := overlayPath(s.path(id), profile)
+		if info, err := s.loader.fs.Stat(overlay); err == nil && !info.IsDir() {
+			overlays = append(overlays, overlay)
+		}
+	}
+
+	return overlays
+}
+
+// overlayPath returns the secret.<profile>.json overlay next to the secret.json at secretPath
+func overlayPath(secretPath string, profile string) string {
+	return path.Join(path.Dir(secretPath), fmt.Sprintf("secret.%s.json", profile))
+}
+
+func (s *documentSet) read(file string) ([]byte, error) {
+	data, err := s.readFile(s.loader.fs, file)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.loader.decoding {
+		if err := checkDuplicateKeys(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	return data, nil
+}
+
+// readFile reads file from fs and records it among the files of the load
+func (s *documentSet) readFile(fs FileSystemInterface, file string) ([]byte, error) {
+	data, err := fs.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if ownsReads(fs) {
+		s.buffers = append(s.buffers, data)
+	}
+
+	for _, existing := range s.files {
+		if existing == file {
+			return data, nil
+		}
+	}
+
+	s.files = append(s.files, file)
+	return data, nil
+}
+
+// resolvers returns the resolvers of the loader with file resolvers reading through s,
+// so the files behind ${file:...} are listed in Paths and watched by the Cache
+func (s *documentSet) resolvers() map[string]Resolver {
+	resolvers := make(map[string]Resolver, len(s.loader.resolvers))
+	for scheme, resolver := range s.loader.resolvers {
+		if file, ok := resolver.(*FileResolver); ok {
+			resolver = ResolverFunc(func(reference string) (string, error) {
+				data, err := s.readFile(file.FS, reference)
+				return fileValue(reference, data, err)
+			})
+		}
+
+		resolvers[scheme] = resolver
+	}
+
+	return resolvers
+}
+
+// document returns the resolved document of secret id ready to be decoded
+func (s *documentSet) document(id string) ([]byte, error) {
+	data, err := s.read(s.path(id))
+	if err != nil {
+		return nil, err
+	}
+
+	overlays := s.overlays(id)
+	if len(overlays) == 0 && !bytes.Contains(data, []byte("${")) && !bytes.Contains(data, []byte(`"$ref"`)) {
+		return data, nil
+	}
+
+	tree, err := s.parse(id, data, overlays)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := s.resolveRefs(tree, id, "", []string{id + "#"})
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := json.Marshal(resolved)
+	s.buffers = append(s.buffers, document)
+	return document, err
+}
+
+// track keeps data to be wiped with the buffers read from files
+func (s *documentSet) track(data []byte) []byte {
+	s.buffers = append(s.buffers, data)
+	return data
+}
+
+// ownsReads reports whether fs returns a buffer of its own to every ReadFile, which the loader
+// may wipe. Other file systems may hand out buffers they keep, like a cache of the files
+func ownsReads(fs FileSystemInterface) bool {
+	_, ok := fs.(*RealFileSystem)
+	return ok
+}
+
+// wipe zeroes every buffer produced while loading and those read from a file system owning
+// its reads, so the raw secret documents don't linger in memory after decoding
+func (s *documentSet) wipe() {
+	for _, buf := range s.buffers {
+		clear(buf)
+	}
+
+	s.buffers = nil
+}
+
+// tree returns the merged and interpolated document of secret id, read once per load
+func (s *documentSet) tree(id string) (interface{}, error) {
+	if tree, ok := s.trees[id]; ok {
+		return tree, nil
+	}
+
+	data, err := s.read(s.path(id))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.parse(id, data, s.overlays(id))
+}
+
+func (s *documentSet) parse(id string, data []byte, overlays []string) (interface{}, error) {
+	tree, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, overlay := range overlays {
+		overlayData, err := s.read(overlay)
+		if err != nil {
+			return nil, err
+		}
+
+		overlayTree, err := parseDocument(overlayData)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", overlay, err)
+		}
+
+		tree = mergeDocuments(tree, overlayTree)
+	}
+
+	if !s.loader.strict {
+		interpolator := &interpolator{resolvers: s.resolvers(), nested: s.loader.nested}
+		resolved, err := interpolator.interpolate(tree)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.path(id), err)
+		}
+
+		tree = resolved
+	}
+
+	s.trees[id] = tree
+	return tree, nil
+}
+
+func parseDocument(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// mergeDocuments deep merges overlay into base, objects are merged key by key
+// while arrays and scalars of overlay replace those of base
+func mergeDocuments(base interface{}, overlay interface{}) interface{} {
+	baseMap, ok := base.(map[string]interface{})
+	overlayMap, overlayOk := overlay.(map[string]interface{})
+	if !ok || !overlayOk {
+		return overlay
+	}
+
+	merged := make(map[string]interface{}, len(baseMap)+len(overlayMap))
+	for key, value := range baseMap {
+		merged[key] = value
+	}
+
+	for key, value := range overlayMap {
+		if existing, ok := merged[key]; ok {
+			merged[key] = mergeDocuments(existing, value)
+		} else {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
+
+// resolveRefs replaces every {"$ref": "typ-name#/json/pointer"} object of node by the value it points to,
+// a reference without typ-name points into the document it appears in
+func (s *documentSet) resolveRefs(node interface{}, id string, fieldPath string, stack []string) (interface{}, error) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := n["$ref"].(string); ok && len(n) == 1 {
+			return s.resolveRef(ref, id, fieldPath, stack)
+		}
+
+		resolved := make(map[string]interface{}, len(n))
+		for key, value := range n {
+			v, err := s.resolveRefs(value, id, joinFieldPath(fieldPath, key), stack)
+			if err != nil {
+				return nil, err
+			}
+
+			resolved[key] = v
+		}
+
+		return resolved, nil
+	case []interface{}:
+		resolved := make([]interface{}, len(n))
+		for idx, value := range n {
+			v, err := s.resolveRefs(value, id, fmt.Sprintf("%s[%d]", fieldPath, idx), stack)
+			if err != nil {
+				return nil, err
+			}
+
+			resolved[idx] = v
+		}
+
+		return resolved, nil
+	}
+
+	return node, nil
+}
+
+func (s *documentSet) resolveRef(ref string, id string, fieldPath string, stack []string) (interface{}, error) {
+	target, pointer, _ := strings.Cut(ref, "#")
+	if target == "" {
+		target = id
+	}
+
+	if strings.ContainsAny(target, `/\`) || target == "." || target == ".." {
+		return nil, fmt.Errorf("%s: %s $ref %q: should name a typ-name secret of the same root", s.path(id), fieldPath, ref)
+	}
+
+	key := target + "#" + pointer
+	for _, visited := range stack {
+		if visited == key {
+			return nil, fmt.Errorf("%s: $ref cycle: %s", s.path(id), strings.Join(append(stack, key), " -> "))
+		}
+	}
+
+	if target != id {
+		s.graph.add(id, target)
+	}
+
+	tree, err := s.tree(target)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s $ref %q: %w", s.path(id), fieldPath, ref, err)
+	}
+
+	value, err := lookupPointer(tree, pointer)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s $ref %q: %w", s.path(id), fieldPath, ref, err)
+	}
+
+	return s.resolveRefs(value, target, fieldPath, append(stack, key))
+}
+
+// lookupPointer returns the value at the RFC 6901 JSON pointer within tree
+func lookupPointer(tree interface{}, pointer string) (interface{}, error) {
+	if pointer == "" {
+		return tree, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("json pointer %q should start with /", pointer)
+	}
+
+	node := tree
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch n := node.(type) {
+		case map[string]interface{}:
+			value, ok := n[token]
+			if !ok {
+				return nil, fmt.Errorf("json pointer %q: key %q %w", pointer, token, os.ErrNotExist)
+			}
+
+			node = value
+		case []interface{}:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(n) {
+				return nil, fmt.Errorf("json pointer %q: index %q %w", pointer, token, os.ErrNotExist)
+			}
+
+			node = n[idx]
+		default:
+			return nil, fmt.Errorf("json pointer %q: %q is not an object or array", pointer, token)
+		}
+	}
+
+	return node, nil
+}
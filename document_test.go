@@ -0,0 +1,90 @@
+package secret
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossSecretReferences(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("shared-main/secret.json", []byte(`{
+		"host": "shared.internal",
+		"credentials": {"password": "shared_password"},
+		"escaped/key": "escaped"
+	}`))
+	mockFS.AddFile("database-app/secret.json", []byte(`{
+		"writer": {
+			"adapter": "mysql",
+			"params": {
+				"host": {"$ref": "shared-main#/host"},
+				"username": {"$ref": "shared-main#/escaped~1key"},
+				"password": {"$ref": "shared-main#/credentials/password"}
+			}
+		},
+		"reader": {"$ref": "#/writer"}
+	}`))
+	mockFS.AddFile("redis-app/secret.json", []byte(`{"master": {"host": {"$ref": "shared-main#/host"}}}`))
+
+	loader := helper.MockLoader()
+	db := &Database{}
+	assert.NoError(t, loader.Load("database", "app", db))
+	assert.Equal(t, "shared.internal", db.Writer.Params.Host)
+	assert.Equal(t, "escaped", db.Writer.Params.Username)
+	assert.Equal(t, "shared_password", db.Writer.Params.Password.Reveal())
+	assert.Equal(t, db.Writer, db.Reader)
+	assert.False(t, db.Inherited("reader"))
+
+	redis := &Redis{}
+	assert.NoError(t, loader.Load("redis", "app", redis))
+	assert.Equal(t, "shared.internal", redis.Master.Host)
+
+	graph, err := loader.DependencyGraph("database", "app")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"shared-main"}, graph.Dependencies("database-app"))
+	assert.Equal(t, "database-app -> shared-main", graph.String())
+}
+
+func TestCrossSecretReferenceErrors(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("shared-a/secret.json", []byte(`{"host": {"$ref": "shared-b#/host"}}`))
+	mockFS.AddFile("shared-b/secret.json", []byte(`{"host": {"$ref": "shared-a#/host"}}`))
+	mockFS.AddFile("redis-cycle/secret.json", []byte(`{"master": {"host": {"$ref": "shared-a#/host"}}}`))
+	mockFS.AddFile("redis-missing/secret.json", []byte(`{"master": {"host": {"$ref": "shared-none#/host"}}}`))
+	mockFS.AddFile("redis-pointer/secret.json", []byte(`{"master": {"host": {"$ref": "shared-a#/port"}}}`))
+
+	loader := helper.MockLoader()
+	err := loader.Load("redis", "cycle", &Redis{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "$ref cycle: redis-cycle# -> shared-a#/host -> shared-b#/host -> shared-a#/host")
+
+	err = loader.Load("redis", "missing", &Redis{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `master.host $ref "shared-none#/host"`)
+
+	err = loader.Load("redis", "pointer", &Redis{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `json pointer "/port": key "port"`)
+}
+
+func TestCrossSecretReferenceStaysInRoot(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("../../elsewhere/x/secret.json", []byte(`{"pw": "outside"}`))
+	for name, ref := range map[string]string{
+		"parent":    "../../elsewhere/x#/pw",
+		"nested":    "shared/x#/pw",
+		"backslash": `..\\x#/pw`,
+		"dotdot":    "..#/pw",
+	} {
+		mockFS.AddFile("redis-"+name+"/secret.json", []byte(`{"master": {"host": "cache", "password": {"$ref": "`+ref+`"}}}`))
+		err := helper.MockLoader().Load("redis", name, &Redis{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "should name a typ-name secret of the same root")
+	}
+}
@@ -0,0 +1,41 @@
+package secret
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationJSON(t *testing.T) {
+	t.Parallel()
+	var v struct {
+		Text    Duration `json:"text"`
+		Seconds Duration `json:"seconds"`
+		Null    Duration `json:"null"`
+	}
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"text": "1m30s", "seconds": 2.5, "null": null}`), &v))
+	assert.Equal(t, 90*time.Second, v.Text.Duration())
+	assert.Equal(t, 2500*time.Millisecond, v.Seconds.Duration())
+	assert.Zero(t, v.Null)
+
+	data, err := json.Marshal(v.Text)
+	assert.NoError(t, err)
+	assert.Equal(t, `"1m30s"`, string(data))
+
+	assert.EqualError(t, json.Unmarshal([]byte(`{"text": "soon"}`), &v), `invalid duration "soon": time: invalid duration "soon"`)
+	assert.Error(t, json.Unmarshal([]byte(`{"text": true}`), &v))
+}
+
+func TestDurationDefault(t *testing.T) {
+	t.Parallel()
+	var v struct {
+		Timeout Duration `json:"timeout" default:"3s"`
+	}
+
+	assert.NoError(t, applyDefaults(reflect.ValueOf(&v).Elem()))
+	assert.Equal(t, 3*time.Second, v.Timeout.Duration())
+}
@@ -288,8 +288,9 @@ func TestJSONUnmarshalingEdgeCases(t *testing.T) {
 				},
 				"slave": null
 			}`,
-			secretType:  &Redis{},
-			expectError: false,
+			secretType: &Redis{},
+			// loaded before secrets were validated on Load, master.host is now required
+			expectError: true,
 		},
 		{
 			name: "JSON with wrong data types",
@@ -306,7 +307,8 @@ func TestJSONUnmarshalingEdgeCases(t *testing.T) {
 			name:        "Empty JSON object",
 			jsonContent: `{}`,
 			secretType:  &Database{},
-			expectError: false,
+			// loaded before secrets were validated on Load, writer is now required
+			expectError: true,
 		},
 		{
 			name:        "JSON with array instead of object",
@@ -2,10 +2,19 @@ module github.com/yetiz-org/goth-secret
 
 go 1.23
 
-require github.com/stretchr/testify v1.7.0
+require (
+	github.com/gocql/gocql v1.7.0
+	github.com/redis/go-redis/v9 v9.9.0
+	github.com/stretchr/testify v1.7.0
+)
 
 require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/golang/snappy v0.0.3 // indirect
+	github.com/hailocab/go-hostpool v0.0.0-20160125115350-e80d13ce29ed // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/inf.v0 v0.9.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
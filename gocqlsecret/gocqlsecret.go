@@ -0,0 +1,80 @@
+// Package gocqlsecret builds gocql cluster configurations from Cassandra secrets, it lives
+// outside the secret package so only its users depend on gocql:
+//
+//	cluster, err := gocqlsecret.WriterCluster(cassandra)
+//	session, err := cluster.CreateSession()
+package gocqlsecret
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gocql/gocql"
+	secret "github.com/yetiz-org/goth-secret"
+)
+
+// NewCluster returns a cluster configuration for meta: hosts from the endpoints, keyspace,
+// password authentication, TLS, a token and datacenter aware host policy when LocalDC is
+// set, and the port, protocol version, consistency and timeouts when set
+func NewCluster(meta *secret.CassandraMeta) (*gocql.ClusterConfig, error) {
+	cluster := gocql.NewCluster(meta.Endpoints...)
+	cluster.Keyspace = meta.Keyspace
+	if meta.Username != "" || meta.Password != "" {
+		cluster.Authenticator = gocql.PasswordAuthenticator{Username: meta.Username, Password: meta.Password.Reveal()}
+	}
+
+	config, err := meta.TLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("gocqlsecret: %w", err)
+	}
+
+	if config != nil {
+		cluster.SslOpts = &gocql.SslOptions{Config: config, EnableHostVerification: meta.HostVerification}
+	}
+
+	if meta.LocalDC != "" {
+		cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(gocql.DCAwareRoundRobinPolicy(meta.LocalDC))
+	}
+
+	if meta.Port != 0 {
+		cluster.Port = int(meta.Port)
+	}
+
+	if meta.ProtocolVersion != 0 {
+		cluster.ProtoVersion = meta.ProtocolVersion
+	}
+
+	if meta.Consistency != "" {
+		consistency, err := gocql.ParseConsistencyWrapper(strings.ToUpper(meta.Consistency))
+		if err != nil {
+			return nil, fmt.Errorf("gocqlsecret: %w", err)
+		}
+
+		cluster.Consistency = consistency
+	}
+
+	if meta.ConnectTimeout > 0 {
+		cluster.ConnectTimeout = meta.ConnectTimeout.Duration()
+	}
+
+	if meta.Timeout > 0 {
+		cluster.Timeout = meta.Timeout.Duration()
+	}
+
+	return cluster, nil
+}
+
+// WriterCluster returns the cluster configuration of the writer of cassandra
+func WriterCluster(cassandra *secret.Cassandra) (*gocql.ClusterConfig, error) {
+	return NewCluster(&cassandra.Writer)
+}
+
+// ReaderCluster returns the cluster configuration of the reader of cassandra, falling back
+// to the writer when no reader is configured
+func ReaderCluster(cassandra *secret.Cassandra) (*gocql.ClusterConfig, error) {
+	if len(cassandra.Reader.Endpoints) == 0 {
+		return WriterCluster(cassandra)
+	}
+
+	return NewCluster(&cassandra.Reader)
+}
@@ -0,0 +1,75 @@
+package gocqlsecret
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+	secret "github.com/yetiz-org/goth-secret"
+)
+
+func TestWriterCluster(t *testing.T) {
+	certs := secret.CreateTestCertificates(t, t.TempDir())
+	cassandra := &secret.Cassandra{}
+	cassandra.Writer = secret.CassandraMeta{
+		Endpoints:        []string{"c1:9042", "c2:9042"},
+		Keyspace:         "app",
+		Username:         "writer",
+		Password:         "secret",
+		CaPath:           certs.CAPath,
+		CertPath:         certs.CertPath,
+		KeyPath:          certs.KeyPath,
+		HostVerification: true,
+		LocalDC:          "dc1",
+		Port:             9142,
+		ProtocolVersion:  4,
+		Consistency:      "local_quorum",
+		ConnectTimeout:   secret.Duration(2 * time.Second),
+		Timeout:          secret.Duration(5 * time.Second),
+	}
+
+	cluster, err := WriterCluster(cassandra)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c1:9042", "c2:9042"}, cluster.Hosts)
+	assert.Equal(t, "app", cluster.Keyspace)
+	assert.Equal(t, gocql.PasswordAuthenticator{Username: "writer", Password: "secret"}, cluster.Authenticator)
+	assert.True(t, cluster.SslOpts.EnableHostVerification)
+	assert.Len(t, cluster.SslOpts.Config.Certificates, 1)
+	assert.NotNil(t, cluster.SslOpts.Config.RootCAs)
+	assert.NotNil(t, cluster.PoolConfig.HostSelectionPolicy)
+	assert.Equal(t, 9142, cluster.Port)
+	assert.Equal(t, 4, cluster.ProtoVersion)
+	assert.Equal(t, gocql.LocalQuorum, cluster.Consistency)
+	assert.Equal(t, 2*time.Second, cluster.ConnectTimeout)
+	assert.Equal(t, 5*time.Second, cluster.Timeout)
+}
+
+func TestReaderCluster(t *testing.T) {
+	cassandra := &secret.Cassandra{}
+	cassandra.Writer.Endpoints = []string{"writer:9042"}
+
+	cluster, err := ReaderCluster(cassandra)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"writer:9042"}, cluster.Hosts)
+	assert.Nil(t, cluster.Authenticator)
+	assert.Nil(t, cluster.SslOpts)
+	assert.Nil(t, cluster.PoolConfig.HostSelectionPolicy)
+	assert.Equal(t, gocql.NewCluster().Consistency, cluster.Consistency)
+
+	cassandra.Reader.Endpoints = []string{"reader:9042"}
+	cassandra.Reader.Consistency = "most"
+	_, err = ReaderCluster(cassandra)
+	assert.Error(t, err)
+
+	cassandra.Reader.Consistency = "ONE"
+	cluster, err = ReaderCluster(cassandra)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"reader:9042"}, cluster.Hosts)
+	assert.Equal(t, gocql.One, cluster.Consistency)
+
+	cassandra.Reader.CaPath = "/missing/ca.pem"
+	_, err = ReaderCluster(cassandra)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "gocqlsecret: ca:")
+}
@@ -0,0 +1,123 @@
+// Package goredissecret builds go-redis client options from Redis secrets, it lives outside
+// the secret package so only its users depend on go-redis:
+//
+//	client, err := goredissecret.NewClient(redisSecret)
+package goredissecret
+
+import (
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	secret "github.com/yetiz-org/goth-secret"
+)
+
+// NodeOptions returns the options of a client of the single node meta, e.g. a read node
+// chosen by Redis.ReadSelector
+func NodeOptions(meta *secret.RedisMeta) (*redis.Options, error) {
+	config, err := meta.TLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("goredissecret: %w", err)
+	}
+
+	return &redis.Options{
+		Addr:         meta.Addr(),
+		Username:     meta.Username,
+		Password:     meta.Password.Reveal(),
+		DB:           meta.DB,
+		TLSConfig:    config,
+		DialTimeout:  meta.DialTimeout.Duration(),
+		ReadTimeout:  meta.ReadTimeout.Duration(),
+		WriteTimeout: meta.WriteTimeout.Duration(),
+	}, nil
+}
+
+// Options returns the options of a client of the master of a standalone r
+func Options(r *secret.Redis) (*redis.Options, error) {
+	if mode := r.Mode(); mode != secret.TopologyStandalone {
+		return nil, fmt.Errorf("goredissecret: %s topology has no standalone options", mode)
+	}
+
+	return NodeOptions(&r.Master)
+}
+
+// FailoverOptions returns the options of a failover client of a sentinel r, the master
+// section holds the credentials, db and TLS settings of the master and replicas
+func FailoverOptions(r *secret.Redis) (*redis.FailoverOptions, error) {
+	if mode := r.Mode(); mode != secret.TopologySentinel {
+		return nil, fmt.Errorf("goredissecret: %s topology has no failover options", mode)
+	}
+
+	node, err := NodeOptions(&r.Master)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redis.FailoverOptions{
+		MasterName:       r.Sentinel.MasterName,
+		SentinelAddrs:    r.Addrs(),
+		SentinelUsername: r.Sentinel.Username,
+		SentinelPassword: r.Sentinel.Password.Reveal(),
+		Username:         node.Username,
+		Password:         node.Password,
+		DB:               node.DB,
+		TLSConfig:        node.TLSConfig,
+		DialTimeout:      node.DialTimeout,
+		ReadTimeout:      node.ReadTimeout,
+		WriteTimeout:     node.WriteTimeout,
+	}, nil
+}
+
+// ClusterOptions returns the options of a cluster client of a cluster r, the master section
+// holds the credentials and TLS settings of the nodes
+func ClusterOptions(r *secret.Redis) (*redis.ClusterOptions, error) {
+	if mode := r.Mode(); mode != secret.TopologyCluster {
+		return nil, fmt.Errorf("goredissecret: %s topology has no cluster options", mode)
+	}
+
+	if r.Master.DB != 0 {
+		return nil, fmt.Errorf("goredissecret: cluster topology only supports db 0, got %d", r.Master.DB)
+	}
+
+	node, err := NodeOptions(&r.Master)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redis.ClusterOptions{
+		Addrs:        r.Addrs(),
+		Username:     node.Username,
+		Password:     node.Password,
+		TLSConfig:    node.TLSConfig,
+		DialTimeout:  node.DialTimeout,
+		ReadTimeout:  node.ReadTimeout,
+		WriteTimeout: node.WriteTimeout,
+	}, nil
+}
+
+// NewClient returns a client of the topology of r: a *redis.Client for standalone and
+// sentinel, a *redis.ClusterClient for cluster
+func NewClient(r *secret.Redis) (redis.UniversalClient, error) {
+	switch r.Mode() {
+	case secret.TopologySentinel:
+		opts, err := FailoverOptions(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return redis.NewFailoverClient(opts), nil
+	case secret.TopologyCluster:
+		opts, err := ClusterOptions(r)
+		if err != nil {
+			return nil, err
+		}
+
+		return redis.NewClusterClient(opts), nil
+	}
+
+	opts, err := Options(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return redis.NewClient(opts), nil
+}
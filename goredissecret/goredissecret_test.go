@@ -0,0 +1,125 @@
+package goredissecret
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	secret "github.com/yetiz-org/goth-secret"
+)
+
+func roundTrip(t *testing.T, client redis.UniversalClient) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, client.Set(ctx, "key", "value", 0).Err())
+	value, err := client.Get(ctx, "key").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value)
+}
+
+func TestStandaloneClientWithTLSAndACL(t *testing.T) {
+	certs := secret.CreateTestCertificates(t, t.TempDir())
+	serverCert, err := tls.X509KeyPair(certs.CertPEM, certs.KeyPEM)
+	assert.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(certs.CAPEM)
+	server := startRESPServer(t, &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}, nodeHandler("app", "secret", nil))
+
+	r := &secret.Redis{}
+	r.Master = secret.RedisMeta{
+		Host:        "localhost",
+		Port:        uint(server.port()),
+		Username:    "app",
+		Password:    "secret",
+		DB:          2,
+		TLS:         secret.TLS{CA: certs.CAPath, Cert: certs.CertPath, Key: secret.Sensitive(certs.KeyPath)},
+		DialTimeout: secret.Duration(time.Second),
+	}
+
+	opts, err := Options(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost", opts.TLSConfig.ServerName)
+	assert.Equal(t, time.Second, opts.DialTimeout)
+
+	client, err := NewClient(r)
+	assert.NoError(t, err)
+	defer client.Close()
+	assert.IsType(t, &redis.Client{}, client)
+	roundTrip(t, client)
+
+	assert.Contains(t, server.recorded("AUTH"), []string{"auth", "app", "secret"})
+	assert.Contains(t, server.recorded("SELECT"), []string{"select", "2"})
+}
+
+func TestFailoverClient(t *testing.T) {
+	master := startRESPServer(t, nil, nodeHandler("", "secret", nil))
+	sentinel := startRESPServer(t, nil, sentinelHandler("sentinel-secret", "main", master))
+
+	r := &secret.Redis{Topology: secret.TopologySentinel}
+	r.Sentinel = secret.RedisSentinel{MasterName: "main", Addrs: []string{sentinel.addr()}, Password: "sentinel-secret"}
+	r.Master.Password = "secret"
+
+	opts, err := FailoverOptions(r)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{sentinel.addr()}, opts.SentinelAddrs)
+	assert.Equal(t, "sentinel-secret", opts.SentinelPassword)
+	assert.Nil(t, opts.TLSConfig)
+
+	client, err := NewClient(r)
+	assert.NoError(t, err)
+	defer client.Close()
+	roundTrip(t, client)
+
+	assert.NotEmpty(t, sentinel.recorded("SENTINEL"))
+	assert.Contains(t, sentinel.recorded("AUTH"), []string{"auth", "sentinel-secret"})
+	assert.Contains(t, master.recorded("AUTH"), []string{"auth", "secret"})
+}
+
+func TestClusterClient(t *testing.T) {
+	var node *respServer
+	node = startRESPServer(t, nil, nodeHandler("", "", func() string {
+		return arrayReply(arrayReply(intReply(0), intReply(16383), arrayReply(bulkReply("127.0.0.1"), intReply(node.port()), bulkReply("node-1"))))
+	}))
+
+	r := &secret.Redis{Cluster: secret.RedisCluster{Addrs: []string{node.addr()}}}
+	opts, err := ClusterOptions(r)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{node.addr()}, opts.Addrs)
+
+	client, err := NewClient(r)
+	assert.NoError(t, err)
+	defer client.Close()
+	assert.IsType(t, &redis.ClusterClient{}, client)
+	roundTrip(t, client)
+	assert.NotEmpty(t, node.recorded("CLUSTER"))
+}
+
+func TestOptionsErrors(t *testing.T) {
+	cluster := &secret.Redis{Cluster: secret.RedisCluster{Addrs: []string{"n1:6379"}}}
+	_, err := Options(cluster)
+	assert.EqualError(t, err, "goredissecret: cluster topology has no standalone options")
+
+	_, err = FailoverOptions(cluster)
+	assert.EqualError(t, err, "goredissecret: cluster topology has no failover options")
+
+	cluster.Master.DB = 1
+	_, err = NewClient(cluster)
+	assert.EqualError(t, err, "goredissecret: cluster topology only supports db 0, got 1")
+
+	standalone := &secret.Redis{}
+	standalone.Master = secret.RedisMeta{Host: "localhost", Port: 6379, TLS: secret.TLS{CA: "/missing/ca.pem"}}
+	_, err = ClusterOptions(standalone)
+	assert.EqualError(t, err, "goredissecret: standalone topology has no cluster options")
+
+	_, err = NewClient(standalone)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "goredissecret: ca:")
+}
@@ -0,0 +1,240 @@
+package goredissecret
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// respServer is an in-process stand-in for a redis server speaking RESP2, it answers
+// commands with handle and records them
+type respServer struct {
+	listener net.Listener
+	handle   func(conn *respConn, args []string) string
+
+	mu       sync.Mutex
+	commands [][]string
+}
+
+// respConn is the state of one client connection
+type respConn struct {
+	authenticated bool
+	db            int
+}
+
+func startRESPServer(t *testing.T, config *tls.Config, handle func(conn *respConn, args []string) string) *respServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config != nil {
+		listener = tls.NewListener(listener, config)
+	}
+
+	s := &respServer{listener: listener, handle: handle}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *respServer) addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *respServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *respServer) recorded(name string) [][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var commands [][]string
+	for _, command := range s.commands {
+		if strings.EqualFold(command[0], name) {
+			commands = append(commands, command)
+		}
+	}
+
+	return commands
+}
+
+func (s *respServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.serveConn(conn)
+	}
+}
+
+func (s *respServer) serveConn(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	state := &respConn{}
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		s.commands = append(s.commands, args)
+		s.mu.Unlock()
+		if _, err := io.WriteString(conn, s.handle(state, args)); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected %q", line)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+		if err != nil {
+			return nil, err
+		}
+
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+
+		args[i] = string(data[:size])
+	}
+
+	return args, nil
+}
+
+func simpleReply(s string) string {
+	return "+" + s + "\r\n"
+}
+
+func errorReply(s string) string {
+	return "-" + s + "\r\n"
+}
+
+func bulkReply(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func intReply(n int) string {
+	return fmt.Sprintf(":%d\r\n", n)
+}
+
+func arrayReply(items ...string) string {
+	return fmt.Sprintf("*%d\r\n%s", len(items), strings.Join(items, ""))
+}
+
+// nodeHandler answers like a data node requiring username and password, storing SET
+// values per db, cluster answers CLUSTER SLOTS with the whole slot range
+func nodeHandler(username, password string, cluster func() string) func(conn *respConn, args []string) string {
+	var mu sync.Mutex
+	values := map[string]string{}
+	return func(conn *respConn, args []string) string {
+		command := strings.ToUpper(args[0])
+		switch command {
+		case "HELLO":
+			return errorReply("ERR unknown command 'HELLO'")
+		case "AUTH":
+			if len(args) == 3 && args[1] == username && args[2] == password || len(args) == 2 && username == "" && args[1] == password {
+				conn.authenticated = true
+				return simpleReply("OK")
+			}
+
+			return errorReply("WRONGPASS invalid username-password pair")
+		case "CLIENT":
+			return simpleReply("OK")
+		}
+
+		if password != "" && !conn.authenticated {
+			return errorReply("NOAUTH Authentication required.")
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch command {
+		case "PING":
+			return simpleReply("PONG")
+		case "SELECT":
+			conn.db, _ = strconv.Atoi(args[1])
+			return simpleReply("OK")
+		case "SET":
+			values[fmt.Sprintf("%d/%s", conn.db, args[1])] = args[2]
+			return simpleReply("OK")
+		case "GET":
+			if value, ok := values[fmt.Sprintf("%d/%s", conn.db, args[1])]; ok {
+				return bulkReply(value)
+			}
+
+			return "$-1\r\n"
+		case "CLUSTER":
+			if cluster != nil && strings.EqualFold(args[1], "SLOTS") {
+				return cluster()
+			}
+		}
+
+		return errorReply(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+	}
+}
+
+// sentinelHandler answers like a sentinel requiring password and pointing name at master
+func sentinelHandler(password, name string, master *respServer) func(conn *respConn, args []string) string {
+	return func(conn *respConn, args []string) string {
+		switch strings.ToUpper(args[0]) {
+		case "AUTH":
+			if args[len(args)-1] == password {
+				conn.authenticated = true
+				return simpleReply("OK")
+			}
+
+			return errorReply("WRONGPASS invalid username-password pair")
+		case "CLIENT":
+			return simpleReply("OK")
+		case "SUBSCRIBE":
+			return arrayReply(bulkReply("subscribe"), bulkReply(args[1]), intReply(1))
+		}
+
+		if !conn.authenticated {
+			return errorReply("NOAUTH Authentication required.")
+		}
+
+		if strings.ToUpper(args[0]) == "SENTINEL" && len(args) == 3 && args[2] == name {
+			switch strings.ToLower(args[1]) {
+			case "get-master-addr-by-name":
+				return arrayReply(bulkReply("127.0.0.1"), bulkReply(strconv.Itoa(master.port())))
+			case "sentinels", "replicas", "slaves":
+				return arrayReply()
+			}
+		}
+
+		return errorReply(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+	}
+}
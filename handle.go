@@ -0,0 +1,61 @@
+package secret
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Handle holds the current value of a secret and swaps it atomically on reload,
+// so concurrent readers always observe a fully loaded value
+type Handle[T any] struct {
+	loader SecretLoaderInterface
+	typ    string
+	name   string
+	value  atomic.Pointer[T]
+}
+
+// NewHandle loads the secret identified by typ and name and returns a handle holding it
+func NewHandle[T any](typ string, name string) (*Handle[T], error) {
+	return NewHandleWithLoader[T](defaultLoader, typ, name)
+}
+
+// NewHandleWithLoader is NewHandle with reloads going through loader
+func NewHandleWithLoader[T any](loader SecretLoaderInterface, typ string, name string) (*Handle[T], error) {
+	if _, ok := any(new(T)).(Secret); !ok {
+		return nil, fmt.Errorf("*%T should implement Secret", *new(T))
+	}
+
+	h := &Handle[T]{loader: loader, typ: typ, name: name}
+	if err := h.Reload(); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Get returns a snapshot of the current value, later reloads never modify it
+func (h *Handle[T]) Get() T {
+	return *h.value.Load()
+}
+
+// Reload loads the secret into a fresh value and swaps it in,
+// the previous value is kept when loading fails
+func (h *Handle[T]) Reload() error {
+	v := new(T)
+	if err := h.loader.Load(h.typ, h.name, any(v).(Secret)); err != nil {
+		return err
+	}
+
+	h.value.Store(v)
+	return nil
+}
+
+// Type returns the secret type the handle loads
+func (h *Handle[T]) Type() string {
+	return h.typ
+}
+
+// Name returns the secret name the handle loads
+func (h *Handle[T]) Name() string {
+	return h.name
+}
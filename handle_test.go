@@ -0,0 +1,69 @@
+package secret
+
+import (
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandle(t *testing.T) {
+	t.Parallel()
+	loader := NewTestHelper().SetupLoader(t)
+	testDir := path.Join(loader.Root(), "redis-handle")
+	assert.NoError(t, os.MkdirAll(testDir, 0o755))
+	secretDir := path.Join(testDir, "secret.json")
+
+	assert.NoError(t, os.WriteFile(secretDir, []byte(`{"master": {"host": "m1", "port": 6379}}`), 0o644))
+
+	h, err := NewHandleWithLoader[Redis](loader, "redis", "handle")
+	assert.NoError(t, err)
+	assert.Equal(t, "redis", h.Type())
+	assert.Equal(t, "handle", h.Name())
+
+	before := h.Get()
+	assert.Equal(t, "m1", before.Master.Host)
+	assert.Equal(t, "handle", before.Name())
+
+	assert.NoError(t, os.WriteFile(secretDir, []byte(`{"master": {"host": "m2", "port": 6379}}`), 0o644))
+	assert.NoError(t, h.Reload())
+	assert.Equal(t, "m2", h.Get().Master.Host)
+	assert.Equal(t, "m1", before.Master.Host)
+
+	assert.NoError(t, os.WriteFile(secretDir, []byte(`{invalid json`), 0o644))
+	assert.Error(t, h.Reload())
+	assert.Equal(t, "m2", h.Get().Master.Host)
+}
+
+func TestHandleConcurrentReload(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	helper.GetMockFileSystem().AddFile("database-handle/secret.json", []byte(`{"writer": {"adapter": "mysql", "params": {"host": "w", "port": 3306}}}`))
+
+	h, err := NewHandleWithLoader[Database](helper.MockLoader(), "database", "handle")
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, h.Reload())
+		}()
+		go func() {
+			defer wg.Done()
+			db := h.Get()
+			assert.Equal(t, "w", db.Writer.Params.Host)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestHandleRequiresSecret(t *testing.T) {
+	_, err := NewHandle[struct{ Field string }]("test", "handle")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "should implement Secret")
+}
@@ -0,0 +1,201 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrNotFound is returned by resolvers when a reference doesn't exist,
+// letting a ${scheme:reference:-fallback} placeholder fall back
+var ErrNotFound = errors.New("reference not found")
+
+// Resolver resolves the reference of ${scheme:reference} placeholders in secret documents
+type Resolver interface {
+	Resolve(reference string) (string, error)
+}
+
+// ResolverFunc adapts a function to Resolver
+type ResolverFunc func(reference string) (string, error)
+
+func (f ResolverFunc) Resolve(reference string) (string, error) {
+	return f(reference)
+}
+
+// EnvResolver resolves ${env:NAME} from the environment
+type EnvResolver struct {
+	Env EnvironmentInterface
+}
+
+func (r *EnvResolver) Resolve(reference string) (string, error) {
+	if value := r.Env.Getenv(reference); value != "" {
+		return value, nil
+	}
+
+	return "", fmt.Errorf("environment variable %s: %w", reference, ErrNotFound)
+}
+
+// FileResolver resolves ${file:/path} to the file content without trailing newlines
+type FileResolver struct {
+	FS FileSystemInterface
+}
+
+func (r *FileResolver) Resolve(reference string) (string, error) {
+	data, err := r.FS.ReadFile(reference)
+	if err == nil && ownsReads(r.FS) {
+		defer clear(data)
+	}
+
+	return fileValue(reference, data, err)
+}
+
+// fileValue returns the value of ${file:reference} read as data
+func fileValue(reference string, data []byte, err error) (string, error) {
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("file %s: %w", reference, ErrNotFound)
+	} else if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// maxInterpolationDepth bounds how deep resolved values may nest placeholders
+const maxInterpolationDepth = 16
+
+type interpolator struct {
+	resolvers map[string]Resolver
+	nested    map[string]bool
+}
+
+// interpolate resolves placeholders in every string value of the parsed document node,
+// keys are left untouched. Resolved values are inserted literally unless their resolver was
+// registered WithNestedResolver, fallbacks are document text and are interpolated in turn
+func (i *interpolator) interpolate(node interface{}) (interface{}, error) {
+	return i.walk(node, "")
+}
+
+func (i *interpolator) walk(node interface{}, fieldPath string) (interface{}, error) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, value := range n {
+			resolved, err := i.walk(value, joinFieldPath(fieldPath, key))
+			if err != nil {
+				return nil, err
+			}
+
+			n[key] = resolved
+		}
+	case []interface{}:
+		for idx, value := range n {
+			resolved, err := i.walk(value, fmt.Sprintf("%s[%d]", fieldPath, idx))
+			if err != nil {
+				return nil, err
+			}
+
+			n[idx] = resolved
+		}
+	case string:
+		resolved, err := i.expand(n, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fieldPath, err)
+		}
+
+		return resolved, nil
+	}
+
+	return node, nil
+}
+
+// expand replaces the placeholders of s, $${ escapes a literal ${. Text that is not a
+// placeholder of a registered scheme, like the ${w} of a p${w}d password, an unknown scheme
+// or an unterminated ${, is kept literally
+func (i *interpolator) expand(s string, stack []string) (string, error) {
+	var out strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			out.WriteString(s)
+			return out.String(), nil
+		}
+
+		if start > 0 && s[start-1] == '$' {
+			out.WriteString(s[:start-1])
+			out.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+
+		end := closingBrace(s[start:])
+		if end < 0 {
+			out.WriteString(s)
+			return out.String(), nil
+		}
+
+		scheme, reference, ok := strings.Cut(s[start+2:start+end], ":")
+		resolver, known := i.resolvers[scheme]
+		if !ok || !known {
+			out.WriteString(s[:start+end+1])
+			s = s[start+end+1:]
+			continue
+		}
+
+		out.WriteString(s[:start])
+		value, err := i.resolve(resolver, scheme, reference, stack)
+		if err != nil {
+			return "", err
+		}
+
+		out.WriteString(value)
+		s = s[start+end+1:]
+	}
+}
+
+// closingBrace returns the index of the } closing the ${ s starts with, skipping the
+// placeholders nested in a fallback, or -1 when it is unterminated
+func closingBrace(s string) int {
+	depth := 0
+	for idx := 2; idx < len(s); idx++ {
+		switch {
+		case s[idx] == '}' && depth == 0:
+			return idx
+		case s[idx] == '}':
+			depth--
+		case strings.HasPrefix(s[idx:], "${"):
+			depth++
+			idx++
+		}
+	}
+
+	return -1
+}
+
+func (i *interpolator) resolve(resolver Resolver, scheme string, reference string, stack []string) (string, error) {
+	reference, fallback, hasFallback := strings.Cut(reference, ":-")
+	key := scheme + ":" + reference
+	for _, visited := range stack {
+		if visited == key {
+			return "", fmt.Errorf("interpolation cycle: %s", strings.Join(append(stack, key), " -> "))
+		}
+	}
+
+	if len(stack) >= maxInterpolationDepth {
+		return "", fmt.Errorf("interpolation of %s nests deeper than %d", key, maxInterpolationDepth)
+	}
+
+	value, err := resolver.Resolve(reference)
+	if errors.Is(err, ErrNotFound) && hasFallback {
+		return i.expand(fallback, append(stack, key))
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if !i.nested[scheme] {
+		return value, nil
+	}
+
+	return i.expand(value, append(stack, key))
+}
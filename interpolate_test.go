@@ -0,0 +1,121 @@
+package secret
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolation(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	helper.GetMockEnvironment().SetVar("DB_PASSWORD", "env_password")
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("/run/keys/host", []byte("db.internal\n"))
+	mockFS.AddFile("database-interpolated/secret.json", []byte(`{
+		"writer": {
+			"adapter": "mysql",
+			"params": {
+				"host": "${file:/run/keys/host}",
+				"dbname": "${env:DB_NAME:-app}_${env:DB_SUFFIX:-main}",
+				"username": "$${literal}",
+				"password": "${env:DB_PASSWORD}"
+			}
+		}
+	}`))
+
+	db := &Database{}
+	assert.NoError(t, helper.MockLoader().Load("database", "interpolated", db))
+	assert.Equal(t, "db.internal", db.Writer.Params.Host)
+	assert.Equal(t, "app_main", db.Writer.Params.DBName)
+	assert.Equal(t, "${literal}", db.Writer.Params.Username)
+	assert.Equal(t, "env_password", db.Writer.Params.Password.Reveal())
+
+	strict := &Database{}
+	err := helper.MockLoader(WithStrict()).Load("database", "interpolated", strict)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `writer.params.host must be a valid hostname, got "${file:/run/keys/host}"`)
+}
+
+func TestInterpolationErrors(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	helper.GetMockEnvironment().SetVar("A", "${env:B}")
+	helper.GetMockEnvironment().SetVar("B", "${env:A}")
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("redis-cycle/secret.json", []byte(`{"master": {"host": "${env:A}"}}`))
+	mockFS.AddFile("redis-missing/secret.json", []byte(`{"master": {"host": "${env:MISSING}"}}`))
+
+	nested := WithNestedResolver("env", &EnvResolver{Env: helper.GetMockEnvironment()})
+	err := helper.MockLoader(nested).Load("redis", "cycle", &Redis{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "master.host: interpolation cycle: env:A -> env:B -> env:A")
+
+	err = helper.MockLoader().Load("redis", "missing", &Redis{})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestInterpolationKeepsLiteralText(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	helper.GetMockEnvironment().SetVar("A", "resolved")
+	mockFS := helper.GetMockFileSystem()
+	for name, password := range map[string]string{
+		"noscheme":     "p${w}d",
+		"unknown":      "${vault:kv/redis}-${env:A}",
+		"unterminated": "${env:A}-${env:A",
+	} {
+		mockFS.AddFile("redis-"+name+"/secret.json", []byte(`{"master": {"host": "cache", "password": "`+password+`"}}`))
+	}
+
+	for name, password := range map[string]string{
+		"noscheme":     "p${w}d",
+		"unknown":      "${vault:kv/redis}-resolved",
+		"unterminated": "resolved-${env:A",
+	} {
+		redis := &Redis{}
+		assert.NoError(t, helper.MockLoader().Load("redis", name, redis))
+		assert.Equal(t, password, redis.Master.Password.Reveal())
+	}
+}
+
+func TestInterpolationInsertsValuesLiterally(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	helper.GetMockEnvironment().SetVar("PW1", "ab$${cd")
+	helper.GetMockEnvironment().SetVar("PW2", "x${file:/etc/hostname}y")
+	helper.GetMockEnvironment().SetVar("HOST", "fallback.local")
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("/etc/hostname", []byte("leaked\n"))
+	mockFS.AddFile("redis-literal/secret.json", []byte(`{
+		"master": {"host": "${env:MISSING:-${env:HOST}}", "password": "${env:PW1}"},
+		"slave": {"host": "replica", "password": "${env:PW2}"}
+	}`))
+
+	redis := &Redis{}
+	assert.NoError(t, helper.MockLoader().Load("redis", "literal", redis))
+	assert.Equal(t, "ab$${cd", redis.Master.Password.Reveal())
+	assert.Equal(t, "x${file:/etc/hostname}y", redis.Slave.Password.Reveal())
+	assert.Equal(t, "fallback.local", redis.Master.Host)
+	assert.NotContains(t, redis.Paths(), "/etc/hostname")
+}
+
+func TestCustomResolver(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	helper.GetMockFileSystem().AddFile("redis-vault/secret.json", []byte(`{"master": {"host": "${vault:kv/redis}", "port": 6380}}`))
+
+	vault := ResolverFunc(func(reference string) (string, error) {
+		if reference == "kv/redis" {
+			return "vault.redis", nil
+		}
+
+		return "", fmt.Errorf("%s: %w", reference, ErrNotFound)
+	})
+
+	redis := &Redis{}
+	assert.NoError(t, helper.MockLoader(WithResolver("vault", vault)).Load("redis", "vault", redis))
+	assert.Equal(t, "vault.redis", redis.Master.Host)
+	assert.Equal(t, uint(6380), redis.Master.Port)
+}
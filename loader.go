@@ -0,0 +1,344 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// Loader loads secrets from its own root, so callers can scope configuration
+// without touching the package level PATH
+type Loader struct {
+	root          string
+	fs            FileSystemInterface
+	env           EnvironmentInterface
+	resolvers     map[string]Resolver
+	nested        map[string]bool
+	strict        bool
+	decoding      bool
+	profiles      []string
+	onDeprecation func(Deprecation)
+	registry      *Registry
+	secure        bool
+}
+
+// LoaderOption configures a Loader
+type LoaderOption func(*Loader)
+
+// WithRoot sets the directory secrets are loaded from
+func WithRoot(root string) LoaderOption {
+	return func(l *Loader) {
+		l.root = root
+	}
+}
+
+// WithFileSystem sets the file system secrets are read from
+func WithFileSystem(fs FileSystemInterface) LoaderOption {
+	return func(l *Loader) {
+		l.fs = fs
+	}
+}
+
+// WithEnvironment sets the environment used to resolve GOTH_SECRET_PATH
+func WithEnvironment(env EnvironmentInterface) LoaderOption {
+	return func(l *Loader) {
+		l.env = env
+	}
+}
+
+// WithResolver registers the resolver of ${scheme:reference} placeholders,
+// replacing the built-in env and file resolvers when scheme is one of them
+func WithResolver(scheme string, resolver Resolver) LoaderOption {
+	return func(l *Loader) {
+		l.resolvers[scheme] = resolver
+		delete(l.nested, scheme)
+	}
+}
+
+// WithNestedResolver registers the resolver of ${scheme:reference} placeholders like WithResolver,
+// the values it resolves may hold placeholders themselves, which are resolved in turn and
+// whose cycles are reported as errors. Values of other resolvers are inserted literally
+func WithNestedResolver(scheme string, resolver Resolver) LoaderOption {
+	return func(l *Loader) {
+		l.resolvers[scheme] = resolver
+		l.nested[scheme] = true
+	}
+}
+
+// WithStrict makes the loader take documents literally, placeholders are left unresolved
+func WithStrict() LoaderOption {
+	return func(l *Loader) {
+		l.strict = true
+	}
+}
+
+// WithStrictDecoding makes the loader reject documents with unknown keys, keys matching
+// a field only case-insensitively and keys repeated within an object
+func WithStrictDecoding() LoaderOption {
+	return func(l *Loader) {
+		l.decoding = true
+	}
+}
+
+// WithProfile sets the profiles whose secret.<profile>.json overlays are merged over secret.json,
+// in order, replacing the profiles listed in GOTH_SECRET_PROFILE
+func WithProfile(profiles ...string) LoaderOption {
+	return func(l *Loader) {
+		l.profiles = profiles
+	}
+}
+
+// WithDeprecationHook sets the function called for every deprecated key found in a loaded document
+func WithDeprecationHook(hook func(Deprecation)) LoaderOption {
+	return func(l *Loader) {
+		l.onDeprecation = hook
+	}
+}
+
+// WithRegistry sets the registry tracking the sensitive values of loaded secrets,
+// nil stops tracking them
+func WithRegistry(registry *Registry) LoaderOption {
+	return func(l *Loader) {
+		l.registry = registry
+	}
+}
+
+// WithSecureMemory makes the loader move every Sensitive value of loaded secrets into a buffer
+// locked out of swap where permitted, which Destroy wipes and whose use after Destroy panics,
+// like SecretBytes. The strings decoded from the document are left to the garbage collector,
+// and the values are not tracked by the registry, which would keep copies Destroy can't wipe
+func WithSecureMemory() LoaderOption {
+	return func(l *Loader) {
+		l.secure = true
+	}
+}
+
+// NewLoader creates a Loader, without WithRoot it falls back to PATH and GOTH_SECRET_PATH.
+// Placeholders are resolved unless WithStrict is set, which breaks documents holding a literal
+// ${env:...} or ${file:...}, escape it as $${ to keep it. Any other ${...} is left as is
+func NewLoader(opts ...LoaderOption) *Loader {
+	l := &Loader{
+		fs:        &RealFileSystem{},
+		env:       &RealEnvironment{},
+		resolvers: make(map[string]Resolver),
+		nested:    make(map[string]bool),
+		registry:  DefaultRegistry,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if _, ok := l.resolvers["env"]; !ok {
+		l.resolvers["env"] = &EnvResolver{Env: l.env}
+	}
+
+	if _, ok := l.resolvers["file"]; !ok {
+		l.resolvers["file"] = &FileResolver{FS: l.fs}
+	}
+
+	return l
+}
+
+var defaultLoader = NewLoader()
+
+// Root returns the directory the loader reads secrets from
+func (l *Loader) Root() string {
+	if l.root != "" {
+		return l.root
+	}
+
+	if PATH != "" {
+		return PATH
+	}
+
+	return l.env.Getenv("GOTH_SECRET_PATH")
+}
+
+// Profiles returns the active profiles, set WithProfile or as a comma separated GOTH_SECRET_PROFILE
+func (l *Loader) Profiles() []string {
+	if l.profiles != nil {
+		return l.profiles
+	}
+
+	var profiles []string
+	for _, profile := range strings.Split(l.env.Getenv("GOTH_SECRET_PROFILE"), ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return profiles
+}
+
+type rootContextKey struct{}
+
+// ContextWithRoot returns a context carrying a root that overrides the loader root in LoadContext
+func ContextWithRoot(ctx context.Context, root string) context.Context {
+	return context.WithValue(ctx, rootContextKey{}, root)
+}
+
+// RootFromContext returns the root carried by ctx, if any
+func RootFromContext(ctx context.Context) (string, bool) {
+	root, ok := ctx.Value(rootContextKey{}).(string)
+	return root, ok
+}
+
+// Load loads the secret typ-name from the loader root into secret. The secret is validated,
+// see Validator, so documents that used to load with required fields missing, like {} for
+// a Database, or with a ca_path naming no PEM file now fail with a *ValidationError
+func (l *Loader) Load(typ string, name string, secret Secret) error {
+	return l.load(l.Root(), typ, name, secret)
+}
+
+// LoadContext is Load with the root taken from ctx when it carries one
+func (l *Loader) LoadContext(ctx context.Context, typ string, name string, secret Secret) error {
+	root, ok := RootFromContext(ctx)
+	if !ok {
+		root = l.Root()
+	}
+
+	return l.load(root, typ, name, secret)
+}
+
+// DependencyGraph resolves the document of the secret typ-name and returns the secrets it references through $ref
+func (l *Loader) DependencyGraph(typ string, name string) (*DependencyGraph, error) {
+	documents := newDocumentSet(l, l.Root())
+	defer documents.wipe()
+	if _, err := documents.document(fmt.Sprintf("%s-%s", typ, name)); err != nil {
+		return nil, err
+	}
+
+	return documents.graph, nil
+}
+
+func (l *Loader) load(root string, typ string, name string, secret Secret) error {
+	secretValue := reflect.ValueOf(secret)
+	if secretValue.Kind() != reflect.Ptr {
+		return fmt.Errorf("secret should be a pointer")
+	}
+
+	secretElem := secretValue.Elem()
+	if secretElem.Kind() != reflect.Struct {
+		return fmt.Errorf("secret should be a struct")
+	}
+
+	found, defaultSecretField := findDefaultSecret(secretElem)
+	if !found {
+		return fmt.Errorf("struct should have a DefaultSecret field")
+	}
+
+	documents := newDocumentSet(l, root)
+	defer documents.wipe()
+	id := fmt.Sprintf("%s-%s", typ, name)
+	secretPath := documents.path(id)
+	if _, e := l.fs.Stat(secretPath); os.IsNotExist(e) {
+		return e
+	}
+
+	bytes, err := documents.document(id)
+	if err != nil {
+		return err
+	}
+
+	if hasAliases(secretElem.Type()) {
+		rewritten, err := l.rewriteAliases(bytes, secretElem.Type(), secretPath)
+		if err != nil {
+			return err
+		}
+
+		if rewritten != nil {
+			bytes = documents.track(rewritten)
+		}
+	}
+
+	if l.decoding {
+		if err := checkStrictKeys(bytes, secretElem.Type()); err != nil {
+			return fmt.Errorf("%s: %w", secretPath, err)
+		}
+	}
+
+	if err := json.Unmarshal(bytes, secret); err != nil {
+		return err
+	}
+
+	if err := applyDefaults(secretElem); err != nil {
+		return err
+	}
+
+	if err := validateSecret(secretElem, l.fs); err != nil {
+		return err
+	}
+
+	setDefaultSecret(defaultSecretField, name, secretPath, documents.files)
+	setInherited(defaultSecretField, applyInheritance(secretElem))
+	if l.secure {
+		secureSensitive(secretElem)
+	}
+
+	var untrack func()
+	if l.registry != nil {
+		untrack = l.registry.track(secretPath+" "+secretElem.Type().String(), secretElem)
+	}
+
+	setUntrack(defaultSecretField, untrack)
+
+	return nil
+}
+
+// rewriteAliases returns data with the deprecated keys renamed, or nil when it has none
+func (l *Loader) rewriteAliases(data []byte, t reflect.Type, secretPath string) ([]byte, error) {
+	tree, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rewritten := false
+	applyAliases(tree, t, "", func(key string, replacement string) {
+		rewritten = true
+		if l.onDeprecation != nil {
+			l.onDeprecation(Deprecation{File: secretPath, Key: key, Replacement: replacement})
+		}
+	})
+
+	if !rewritten {
+		return nil, nil
+	}
+
+	return json.Marshal(tree)
+}
+
+func setDefaultSecret(defaultSecretField reflect.Value, name string, secretPath string, files []string) {
+	if !defaultSecretField.IsValid() {
+		return
+	}
+
+	nameField := defaultSecretField.FieldByName("_Name")
+	pathField := defaultSecretField.FieldByName("_Path")
+	pathsField := defaultSecretField.FieldByName("_Paths")
+
+	if nameField.IsValid() {
+		v := reflect.NewAt(nameField.Type(), unsafe.Pointer(nameField.UnsafeAddr()))
+		v.Elem().SetString(name)
+	}
+
+	if pathField.IsValid() {
+		v := reflect.NewAt(pathField.Type(), unsafe.Pointer(pathField.UnsafeAddr()))
+		v.Elem().SetString(secretPath)
+	}
+
+	if pathsField.IsValid() {
+		v := reflect.NewAt(pathsField.Type(), unsafe.Pointer(pathsField.UnsafeAddr()))
+		v.Elem().Set(reflect.ValueOf(files))
+	}
+}
+
+func setUntrack(defaultSecretField reflect.Value, untrack func()) {
+	if field := defaultSecretField.FieldByName("_Untrack"); field.IsValid() {
+		reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Set(reflect.ValueOf(untrack))
+	}
+}
@@ -0,0 +1,81 @@
+package secret
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoaderRoot(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	loader := helper.SetupLoader(t)
+
+	_, cleanup := helper.CreateSecretFile(t, "redis", "scoped", CreateRedisSecret("scoped"))
+	defer cleanup()
+
+	redis := &Redis{}
+	assert.NoError(t, loader.Load("redis", "scoped", redis))
+	assert.Equal(t, "scoped", redis.Name())
+	assert.Equal(t, "localhost", redis.Master.Host)
+	assert.Contains(t, redis.Path(), loader.Root())
+
+	assert.Error(t, loader.Load("redis", "missing", &Redis{}))
+}
+
+func TestLoaderContextRoot(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	scoped := helper.SetupLoader(t)
+
+	_, cleanup := helper.CreateSecretFile(t, "database", "ctx", CreateDatabaseSecret("ctx"))
+	defer cleanup()
+
+	loader := NewLoader(WithRoot("/non-existent-path"))
+	assert.Error(t, loader.Load("database", "ctx", &Database{}))
+
+	ctx := ContextWithRoot(context.Background(), scoped.Root())
+	root, ok := RootFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, scoped.Root(), root)
+
+	db := &Database{}
+	assert.NoError(t, loader.LoadContext(ctx, "database", "ctx", db))
+	assert.Equal(t, "localhost", db.Writer.Params.Host)
+
+	_, ok = RootFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func TestLoaderMockFileSystem(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	assert.NoError(t, helper.CreateMockSecretFile("cassandra", "mock", CreateCassandraSecret("mock")))
+
+	c := &Cassandra{}
+	assert.NoError(t, helper.MockLoader().Load("cassandra", "mock", c))
+	assert.Equal(t, "mock", c.Name())
+	assert.Equal(t, "cassandra-mock/secret.json", c.Path())
+	assert.Equal(t, "test_keyspace", c.Writer.Keyspace)
+}
+
+func TestLoaderEnvironmentRoot(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	helper.SetMockPath("/env/path")
+
+	assert.Equal(t, "/env/path", helper.MockLoader().Root())
+
+	assert.Equal(t, "/scoped", helper.MockLoader(WithRoot("/scoped")).Root())
+}
+
+func TestHandleWithLoader(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	assert.NoError(t, helper.CreateMockSecretFile("redis", "mock", CreateRedisSecret("mock")))
+
+	h, err := NewHandleWithLoader[Redis](helper.MockLoader(), "redis", "mock")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(6379), h.Get().Master.Port)
+}
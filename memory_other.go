@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package secret
+
+// lockMemory reports false where memory can't be locked out of swap
+func lockMemory(buf []byte) bool {
+	return false
+}
+
+func unlockMemory(buf []byte) {}
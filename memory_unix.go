@@ -0,0 +1,61 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package secret
+
+import (
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	pageSize    = uintptr(syscall.Getpagesize())
+	lockedMu    sync.Mutex
+	lockedPages = make(map[uintptr]int)
+)
+
+// lockMemory tries to lock buf out of swap, it fails silently when RLIMIT_MEMLOCK or privileges
+// don't permit it. Heap buffers may share pages and munlock doesn't nest, so locked pages are
+// counted for unlockMemory
+func lockMemory(buf []byte) bool {
+	lockedMu.Lock()
+	defer lockedMu.Unlock()
+	if syscall.Mlock(buf) != nil {
+		return false
+	}
+
+	start, end := pageRange(buf)
+	for page := start; page < end; page += pageSize {
+		lockedPages[page]++
+	}
+
+	return true
+}
+
+// unlockMemory unlocks the pages of buf no other locked buffer shares, only the first and
+// last page can be shared so the pages to unlock are contiguous
+func unlockMemory(buf []byte) {
+	lockedMu.Lock()
+	defer lockedMu.Unlock()
+	base := uintptr(unsafe.Pointer(unsafe.SliceData(buf)))
+	start, end := pageRange(buf)
+	from, to := uintptr(len(buf)), uintptr(0)
+	for page := start; page < end; page += pageSize {
+		if lockedPages[page]--; lockedPages[page] > 0 {
+			continue
+		}
+
+		delete(lockedPages, page)
+		from = min(from, max(page, base)-base)
+		to = max(to, min(page+pageSize, base+uintptr(len(buf)))-base)
+	}
+
+	if from < to {
+		syscall.Munlock(buf[from:to])
+	}
+}
+
+func pageRange(buf []byte) (uintptr, uintptr) {
+	base := uintptr(unsafe.Pointer(unsafe.SliceData(buf)))
+	return base &^ (pageSize - 1), (base + uintptr(len(buf)) + pageSize - 1) &^ (pageSize - 1)
+}
@@ -1,7 +1,6 @@
 package secret
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -58,9 +57,9 @@ func (mfs *MockFileSystem) AddFileError(path string, err error) {
 	mfs.errors[path] = err
 }
 
-// AddSecretFile adds a secret file with JSON content
+// AddSecretFile adds a secret file with JSON content, Sensitive values are written unredacted
 func (mfs *MockFileSystem) AddSecretFile(typ, name string, content interface{}) error {
-	jsonData, err := json.Marshal(content)
+	jsonData, err := marshalRevealed(content)
 	if err != nil {
 		return err
 	}
@@ -31,7 +31,7 @@ func (b *DatabaseMockBuilder) WithWriter(adapter, host string, port uint, dbname
 	b.database.Writer.Params.Port = port
 	b.database.Writer.Params.DBName = dbname
 	b.database.Writer.Params.Username = username
-	b.database.Writer.Params.Password = password
+	b.database.Writer.Params.Password = Sensitive(password)
 	return b
 }
 
@@ -48,7 +48,7 @@ func (b *DatabaseMockBuilder) WithReader(adapter, host string, port uint, dbname
 	b.database.Reader.Params.Port = port
 	b.database.Reader.Params.DBName = dbname
 	b.database.Reader.Params.Username = username
-	b.database.Reader.Params.Password = password
+	b.database.Reader.Params.Password = Sensitive(password)
 	return b
 }
 
@@ -149,7 +149,7 @@ func (b *CassandraMockBuilder) WithWriter(endpoints []string, keyspace, username
 	b.cassandra.Writer.Endpoints = endpoints
 	b.cassandra.Writer.Keyspace = keyspace
 	b.cassandra.Writer.Username = username
-	b.cassandra.Writer.Password = password
+	b.cassandra.Writer.Password = Sensitive(password)
 	b.cassandra.Writer.CaPath = caPath
 	return b
 }
@@ -159,7 +159,7 @@ func (b *CassandraMockBuilder) WithReader(endpoints []string, keyspace, username
 	b.cassandra.Reader.Endpoints = endpoints
 	b.cassandra.Reader.Keyspace = keyspace
 	b.cassandra.Reader.Username = username
-	b.cassandra.Reader.Password = password
+	b.cassandra.Reader.Password = Sensitive(password)
 	b.cassandra.Reader.CaPath = caPath
 	return b
 }
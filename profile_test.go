@@ -0,0 +1,76 @@
+package secret
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileOverlays(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	helper.GetMockEnvironment().SetVar("GOTH_SECRET_PROFILE", "prod, eu")
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("cassandra-main/secret.json", []byte(`{
+		"writer": {"endpoints": ["dev:9042"], "keyspace": "app", "username": "dev", "password": "dev"}
+	}`))
+	mockFS.AddFile("cassandra-main/secret.prod.json", []byte(`{
+		"writer": {"endpoints": ["prod1:9042", "prod2:9042"], "username": "prod", "password": "prod"}
+	}`))
+	mockFS.AddFile("cassandra-main/secret.eu.json", []byte(`{"writer": {"password": "eu"}}`))
+
+	loader := helper.MockLoader()
+	assert.Equal(t, []string{"prod", "eu"}, loader.Profiles())
+
+	c := &Cassandra{}
+	assert.NoError(t, loader.Load("cassandra", "main", c))
+	assert.Equal(t, []string{"prod1:9042", "prod2:9042"}, c.Writer.Endpoints)
+	assert.Equal(t, "app", c.Writer.Keyspace)
+	assert.Equal(t, "prod", c.Writer.Username)
+	assert.Equal(t, "eu", c.Writer.Password.Reveal())
+	assert.Equal(t, "cassandra-main/secret.json", c.Path())
+	assert.Equal(t, []string{
+		"cassandra-main/secret.json",
+		"cassandra-main/secret.prod.json",
+		"cassandra-main/secret.eu.json",
+	}, c.Paths())
+
+	c = &Cassandra{}
+	assert.NoError(t, helper.MockLoader(WithProfile("staging")).Load("cassandra", "main", c))
+	assert.Equal(t, []string{"dev:9042"}, c.Writer.Endpoints)
+	assert.Equal(t, []string{"cassandra-main/secret.json"}, c.Paths())
+}
+
+func TestProfileOverlayWithReferences(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("shared-main/secret.json", []byte(`{"host": "dev.redis"}`))
+	mockFS.AddFile("shared-main/secret.prod.json", []byte(`{"host": "prod.redis"}`))
+	mockFS.AddFile("redis-main/secret.json", []byte(`{"master": {"host": {"$ref": "shared-main#/host"}}}`))
+
+	redis := &Redis{}
+	assert.NoError(t, helper.MockLoader(WithProfile("prod")).Load("redis", "main", redis))
+	assert.Equal(t, "prod.redis", redis.Master.Host)
+	assert.Equal(t, []string{
+		"redis-main/secret.json",
+		"shared-main/secret.json",
+		"shared-main/secret.prod.json",
+	}, redis.Paths())
+}
+
+func TestCacheInvalidatesOnOverlayChange(t *testing.T) {
+	fs := &countingFileSystem{MockFileSystem: NewMockFileSystem()}
+	fs.AddFile("redis-main/secret.json", []byte(`{"master": {"host": "dev.redis"}}`))
+	fs.AddFile("redis-main/secret.prod.json", []byte(`{"master": {"host": "prod.redis"}}`))
+	cache := NewCache(NewLoader(WithFileSystem(fs), WithProfile("prod")), 0)
+
+	assert.NoError(t, cache.Load("redis", "main", &Redis{}))
+	fs.AddFile("redis-main/secret.prod.json", []byte(`{"master": {"host": "prod2.redis"}}`))
+	fs.stats["redis-main/secret.prod.json"].(*mockFileInfo).modTime = time.Now().Add(time.Second)
+
+	redis := &Redis{}
+	assert.NoError(t, cache.Load("redis", "main", redis))
+	assert.Equal(t, "prod2.redis", redis.Master.Host)
+}
@@ -0,0 +1,274 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// MinScrubLength is the length below which sensitive values are not tracked,
+// scrubbing very short values would mangle unrelated log output
+const MinScrubLength = 4
+
+// Registry tracks the sensitive values of loaded secrets so logs can be scrubbed of them.
+// Each loaded secret replaces the values it was tracked with before, so rotated values
+// don't pile up across reloads
+type Registry struct {
+	mu         sync.Mutex
+	values     map[string]struct{}
+	sources    map[string]trackedSource
+	generation uint64
+	patterns   []string
+	matcher    atomic.Pointer[matcher]
+}
+
+// trackedSource holds the values of the last load of a secret, generation tells that load
+// apart from earlier ones
+type trackedSource struct {
+	values     []string
+	generation uint64
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{values: make(map[string]struct{}), sources: make(map[string]trackedSource)}
+}
+
+// DefaultRegistry collects the sensitive values of every Loader without WithRegistry
+var DefaultRegistry = NewRegistry()
+
+// Add tracks values, values shorter than MinScrubLength are ignored
+func (r *Registry) Add(values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, value := range values {
+		if len(value) >= MinScrubLength {
+			r.values[value] = struct{}{}
+		}
+	}
+
+	r.rebuild()
+}
+
+// Remove stops tracking values, whether added or loaded
+func (r *Registry) Remove(values ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, value := range values {
+		delete(r.values, value)
+		for source, tracked := range r.sources {
+			tracked.values = slices.DeleteFunc(tracked.values, func(v string) bool { return v == value })
+			r.sources[source] = tracked
+		}
+	}
+
+	r.rebuild()
+}
+
+// Len returns the number of tracked values
+func (r *Registry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.patterns)
+}
+
+// set replaces the values tracked for source, the secret they were loaded from, and returns
+// the generation identifying them for untrack
+func (r *Registry) set(source string, values []string) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.generation++
+	values = slices.DeleteFunc(values, func(v string) bool { return len(v) < MinScrubLength })
+	if len(values) == 0 {
+		delete(r.sources, source)
+	} else {
+		r.sources[source] = trackedSource{values: values, generation: r.generation}
+	}
+
+	r.rebuild()
+	return r.generation
+}
+
+// untrack stops tracking the values of source unless they were replaced since generation
+func (r *Registry) untrack(source string, generation uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if tracked, ok := r.sources[source]; ok && tracked.generation == generation {
+		delete(r.sources, source)
+		r.rebuild()
+	}
+}
+
+// rebuild replaces the matcher when the tracked values changed
+func (r *Registry) rebuild() {
+	union := make(map[string]struct{}, len(r.values))
+	for value := range r.values {
+		union[value] = struct{}{}
+	}
+
+	for _, tracked := range r.sources {
+		for _, value := range tracked.values {
+			union[value] = struct{}{}
+		}
+	}
+
+	patterns := make([]string, 0, len(union))
+	for value := range union {
+		patterns = append(patterns, value)
+	}
+
+	sort.Strings(patterns)
+	if slices.Equal(patterns, r.patterns) {
+		return
+	}
+
+	r.patterns = patterns
+	if len(patterns) == 0 {
+		r.matcher.Store(nil)
+		return
+	}
+
+	r.matcher.Store(newMatcher(patterns))
+}
+
+// Scrub returns s with every tracked value replaced by RedactedValue
+func (r *Registry) Scrub(s string) string {
+	m := r.matcher.Load()
+	if m == nil {
+		return s
+	}
+
+	return m.replace(s, RedactedValue)
+}
+
+// track replaces the values tracked for the secret at source with the Sensitive values held
+// by v and returns the function untracking them. SecretBytes and values loaded WithSecureMemory
+// are left out as tracking them would keep a copy that Destroy can't wipe
+func (r *Registry) track(source string, v reflect.Value) func() {
+	var values []string
+	collectSensitive(v, &values)
+	generation := r.set(source, values)
+	return func() {
+		r.untrack(source, generation)
+	}
+}
+
+func collectSensitive(v reflect.Value, values *[]string) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Type() == sensitiveType && !Sensitive(v.String()).secure() {
+			*values = append(*values, v.String())
+		}
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			collectSensitive(v.Elem(), values)
+		}
+	case reflect.Struct:
+		if v.Type() == secretBytesType {
+			return
+		}
+
+		for i := 0; i < v.NumField(); i++ {
+			collectSensitive(v.Field(i), values)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			collectSensitive(v.Index(i), values)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			collectSensitive(iter.Value(), values)
+		}
+	}
+}
+
+// ScrubHandler is a slog.Handler replacing the sensitive values tracked by a Registry
+// in messages and attributes before passing records to the next handler
+type ScrubHandler struct {
+	next     slog.Handler
+	registry *Registry
+}
+
+// NewScrubHandler wraps next, scrubbing the values tracked by registry, DefaultRegistry when nil
+func NewScrubHandler(next slog.Handler, registry *Registry) *ScrubHandler {
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+
+	return &ScrubHandler{next: next, registry: registry}
+}
+
+func (h *ScrubHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *ScrubHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.registry.matcher.Load() == nil {
+		return h.next.Handle(ctx, record)
+	}
+
+	scrubbed := slog.NewRecord(record.Time, record.Level, h.registry.Scrub(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		scrubbed.AddAttrs(h.scrubAttr(attr))
+		return true
+	})
+
+	return h.next.Handle(ctx, scrubbed)
+}
+
+// WithAttrs scrubs attrs once, values tracked afterwards are not scrubbed from them
+func (h *ScrubHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbed := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		scrubbed[i] = h.scrubAttr(attr)
+	}
+
+	return &ScrubHandler{next: h.next.WithAttrs(scrubbed), registry: h.registry}
+}
+
+func (h *ScrubHandler) WithGroup(name string) slog.Handler {
+	return &ScrubHandler{next: h.next.WithGroup(name), registry: h.registry}
+}
+
+func (h *ScrubHandler) scrubAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, h.registry.Scrub(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		scrubbed := make([]slog.Attr, len(group))
+		for i, member := range group {
+			scrubbed[i] = h.scrubAttr(member)
+		}
+
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(scrubbed...)}
+	case slog.KindAny:
+		// byte slices, errors and Stringers are scrubbed as the text they are logged as, a value
+		// holding a tracked value is replaced by its scrubbed text. Other values are not formatted
+		// here, they should implement slog.LogValuer to be scrubbed
+		var s string
+		switch v := value.Any().(type) {
+		case []byte:
+			s = string(v)
+		case error:
+			s = v.Error()
+		case fmt.Stringer:
+			s = v.String()
+		default:
+			return slog.Attr{Key: attr.Key, Value: value}
+		}
+
+		if scrubbed := h.registry.Scrub(s); scrubbed != s {
+			return slog.String(attr.Key, scrubbed)
+		}
+	}
+
+	return slog.Attr{Key: attr.Key, Value: value}
+}
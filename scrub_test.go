@@ -0,0 +1,130 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcherReplace(t *testing.T) {
+	t.Parallel()
+	m := newMatcher([]string{"he", "she", "hers", "secret"})
+	assert.Equal(t, "no match", m.replace("no match", "*"))
+	assert.Equal(t, "u* and *", m.replace("ushers and he", "*"))
+	assert.Equal(t, "*", m.replace("secret", "*"))
+	assert.Equal(t, "a*b", m.replace("asecretsecretb", "*"))
+	assert.Equal(t, "*t", m.replace("shehet", "*"))
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+	r := NewRegistry()
+	assert.Equal(t, "pw", r.Scrub("pw"))
+
+	r.Add("pw", "hunter2", "hunter2")
+	assert.Equal(t, 1, r.Len())
+	assert.Equal(t, "dsn user:******@tcp(db) pw", r.Scrub("dsn user:hunter2@tcp(db) pw"))
+
+	r.Remove("hunter2")
+	assert.Equal(t, "user:hunter2", r.Scrub("user:hunter2"))
+}
+
+func TestScrubHandler(t *testing.T) {
+	t.Parallel()
+	registry := NewRegistry()
+	helper := NewTestHelper()
+	assert.NoError(t, helper.CreateMockSecretFile("database", "scrub", CreateDatabaseSecret("scrub")))
+
+	db := &Database{}
+	assert.NoError(t, helper.MockLoader(WithRegistry(registry)).Load("database", "scrub", db))
+	assert.Equal(t, 2, registry.Len())
+
+	var buf bytes.Buffer
+	logger := slog.New(NewScrubHandler(slog.NewTextHandler(&buf, nil), registry)).With("bound", "test_password")
+	dsn := "test_user:" + db.Writer.Params.Password.Reveal() + "@tcp(localhost)/test_db"
+	logger.WithGroup("g").Info("connecting with "+dsn,
+		"dsn", dsn,
+		"err", errors.New("auth failed for readonly_password"),
+		slog.Group("nested", "value", "readonly_password"),
+		"count", 3,
+	)
+
+	out := buf.String()
+	assert.NotContains(t, out, "test_password")
+	assert.NotContains(t, out, "readonly_password")
+	assert.Contains(t, out, `msg="connecting with test_user:******@tcp(localhost)/test_db"`)
+	assert.Contains(t, out, "bound=******")
+	assert.Contains(t, out, `g.err="auth failed for ******"`)
+	assert.Contains(t, out, "g.nested.value=******")
+	assert.Contains(t, out, "g.count=3")
+}
+
+func TestRegistryReplacesReloadedValues(t *testing.T) {
+	t.Parallel()
+	registry := NewRegistry()
+	helper := NewTestHelper()
+	loader := helper.MockLoader(WithRegistry(registry))
+	for _, password := range []string{"first_password", "second_password", "second_password"} {
+		helper.GetMockFileSystem().AddFile("redis-rotated/secret.json", []byte(`{"master": {"host": "cache", "password": "`+password+`"}}`))
+		assert.NoError(t, loader.Load("redis", "rotated", &Redis{}))
+	}
+
+	assert.Equal(t, 1, registry.Len())
+	assert.Equal(t, "first_password ******", registry.Scrub("first_password second_password"))
+
+	registry.Add("manual_value")
+	registry.Remove("second_password")
+	assert.Equal(t, 1, registry.Len())
+	assert.Equal(t, "second_password ******", registry.Scrub("second_password manual_value"))
+}
+
+func TestScrubHandlerFormatsValues(t *testing.T) {
+	t.Parallel()
+	registry := NewRegistry()
+	registry.Add("hunter22")
+
+	var buf bytes.Buffer
+	logger := slog.New(NewScrubHandler(slog.NewJSONHandler(&buf, nil), registry))
+	dsn, err := url.Parse("mysql://user:hunter22@db/app")
+	assert.NoError(t, err)
+	logger.Info("connect",
+		"err", fmt.Errorf("dial user:hunter22@tcp(db)/app: refused"),
+		"url", dsn,
+		"raw", []byte("user:hunter22@tcp(db)/app"),
+		"port", []int{3306},
+	)
+
+	out := buf.String()
+	assert.NotContains(t, out, "hunter22")
+	assert.Contains(t, out, `"err":"dial user:******@tcp(db)/app: refused"`)
+	assert.Contains(t, out, `"url":"mysql://user:******@db/app"`)
+	assert.Contains(t, out, `"raw":"user:******@tcp(db)/app"`)
+	assert.Contains(t, out, `"port":[3306]`)
+}
+
+func TestLoaderWithoutRegistry(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	assert.NoError(t, helper.CreateMockSecretFile("database", "untracked", CreateDatabaseSecret("untracked")))
+	assert.NoError(t, helper.MockLoader(WithRegistry(nil)).Load("database", "untracked", &Database{}))
+}
+
+func BenchmarkScrubHandler(b *testing.B) {
+	registry := NewRegistry()
+	for i := 0; i < 100; i++ {
+		registry.Add(strings.Repeat("x", 8) + string(rune('a'+i%26)) + strings.Repeat("y", i%5))
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(NewScrubHandler(slog.NewTextHandler(&buf, nil), registry))
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		logger.Info("request served", "path", "/api/v1/users", "status", 200)
+	}
+}
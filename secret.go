@@ -1,16 +1,15 @@
 package secret
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"os"
-	"path"
 	"reflect"
-	"unsafe"
 )
 
+// PATH is the default secret root, Loader instances created WithRoot ignore it
 var PATH = ""
 
+// Path returns the default secret root, PATH or GOTH_SECRET_PATH when PATH is empty
 func Path() string {
 	if PATH == "" {
 		return os.Getenv("GOTH_SECRET_PATH")
@@ -20,8 +19,11 @@ func Path() string {
 }
 
 type DefaultSecret struct {
-	_Name string
-	_Path string
+	_Name      string
+	_Path      string
+	_Paths     []string
+	_Inherited []string
+	_Untrack   func()
 }
 
 func (d *DefaultSecret) Name() string {
@@ -32,6 +34,33 @@ func (d *DefaultSecret) Path() string {
 	return d._Path
 }
 
+// Paths returns every file that contributed to the secret, secret.json first,
+// then the profile overlays, the documents it references and the files read by ${file:...}
+func (d *DefaultSecret) Paths() []string {
+	return d._Paths
+}
+
+// Inherited reports whether field, named as in the document, was omitted
+// and inherited from its sibling, like a Reader inheriting the Writer
+func (d *DefaultSecret) Inherited(field string) bool {
+	for _, inherited := range d._Inherited {
+		if inherited == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// untrack stops the registry of the loader scrubbing the values the secret was loaded with,
+// unless the secret was loaded again since
+func (d *DefaultSecret) untrack() {
+	if d._Untrack != nil {
+		d._Untrack()
+		d._Untrack = nil
+	}
+}
+
 func findDefaultSecret(v reflect.Value) (bool, reflect.Value) {
 	if v.Kind() != reflect.Struct {
 		return false, reflect.Value{}
@@ -60,51 +89,12 @@ type Secret interface {
 	Path() string
 }
 
+// Load loads the secret typ-name into secret using the default loader
 func Load(typ string, name string, secret Secret) error {
-	secretValue := reflect.ValueOf(secret)
-	if secretValue.Kind() != reflect.Ptr {
-		return fmt.Errorf("secret should be a pointer")
-	}
-
-	secretElem := secretValue.Elem()
-	if secretElem.Kind() != reflect.Struct {
-		return fmt.Errorf("secret should be a struct")
-	}
-
-	hasDefaultSecret := false
-	found, defaultSecretField := findDefaultSecret(secretElem)
-	if found {
-		hasDefaultSecret = true
-	}
-
-	if !hasDefaultSecret {
-		return fmt.Errorf("struct should have a DefaultSecret field")
-	}
-
-	secretPath := path.Join(Path(), fmt.Sprintf("%s-%s/secret.json", typ, name))
-	if _, e := os.Stat(secretPath); os.IsNotExist(e) {
-		return e
-	}
-	if bytes, err := os.ReadFile(secretPath); err == nil {
-		if err := json.Unmarshal(bytes, secret); err != nil {
-			return err
-		}
-
-		if found && defaultSecretField.IsValid() {
-			nameField := defaultSecretField.FieldByName("_Name")
-			pathField := defaultSecretField.FieldByName("_Path")
-
-			if nameField.IsValid() {
-				v := reflect.NewAt(nameField.Type(), unsafe.Pointer(nameField.UnsafeAddr()))
-				v.Elem().SetString(name)
-			}
-
-			if pathField.IsValid() {
-				v := reflect.NewAt(pathField.Type(), unsafe.Pointer(pathField.UnsafeAddr()))
-				v.Elem().SetString(secretPath)
-			}
-		}
-	}
+	return defaultLoader.Load(typ, name, secret)
+}
 
-	return nil
+// LoadContext loads the secret typ-name into secret using the default loader and the root carried by ctx
+func LoadContext(ctx context.Context, typ string, name string, secret Secret) error {
+	return defaultLoader.LoadContext(ctx, typ, name, secret)
 }
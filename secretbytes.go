@@ -0,0 +1,425 @@
+package secret
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"unicode/utf16"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// SecretBytes holds a sensitive value in a buffer locked out of swap where permitted, unlike
+// Sensitive strings loaded without WithSecureMemory it can be wiped with Destroy. It decodes
+// from a JSON string without an intermediate Go string and is redacted like Sensitive. Using
+// any accessor after Destroy panics
+type SecretBytes struct {
+	buf *secureBuffer
+}
+
+type secureBuffer struct {
+	mu        sync.Mutex
+	data      []byte
+	locked    bool
+	destroyed bool
+}
+
+// minSecureSize keeps buffers out of the tiny allocator, which packs small allocations
+// together and may never run their finalizers
+const minSecureSize = 16
+
+// NewSecretBytes copies value into a new SecretBytes, the caller should wipe value afterwards
+func NewSecretBytes(value []byte) SecretBytes {
+	if len(value) == 0 {
+		return SecretBytes{}
+	}
+
+	// the buffer lives on the heap so slices returned by Bytes keep it alive, it is wiped
+	// and unlocked by a finalizer on the allocation itself once no slice references it
+	size := max(len(value), minSecureSize)
+	data := make([]byte, size)
+	locked := lockMemory(data)
+	runtime.SetFinalizer(&data[0], func(p *byte) {
+		data := unsafe.Slice(p, size)
+		clear(data)
+		if locked {
+			unlockMemory(data)
+		}
+	})
+
+	copy(data, value)
+	return SecretBytes{buf: &secureBuffer{data: data[:len(value):len(value)], locked: locked}}
+}
+
+// secureStrings holds the state of the buffers backing Sensitive values loaded WithSecureMemory,
+// keyed by address. Entries are removed by the finalizer freeing the buffer, before the address
+// can be reused
+var (
+	secureStrings     sync.Map
+	secureStringCount atomic.Int64
+)
+
+type secureString struct {
+	size      int
+	destroyed atomic.Bool
+}
+
+// newSecureSensitive copies value into a buffer allocated like those of SecretBytes and returns
+// a Sensitive reading it, Destroy wipes the buffer in place
+func newSecureSensitive(value string) Sensitive {
+	if value == "" {
+		return ""
+	}
+
+	size := max(len(value), minSecureSize)
+	data := make([]byte, size)
+	locked := lockMemory(data)
+	key := uintptr(unsafe.Pointer(&data[0]))
+	secureStrings.Store(key, &secureString{size: size})
+	secureStringCount.Add(1)
+	runtime.SetFinalizer(&data[0], func(p *byte) {
+		secureStrings.Delete(key)
+		secureStringCount.Add(-1)
+		data := unsafe.Slice(p, size)
+		clear(data)
+		if locked {
+			unlockMemory(data)
+		}
+	})
+
+	copy(data, value)
+	return Sensitive(unsafe.String(&data[0], len(value)))
+}
+
+// secureState returns the state of the buffer backing s when s was loaded WithSecureMemory
+func (s Sensitive) secureState() (*secureString, bool) {
+	if s == "" || secureStringCount.Load() == 0 {
+		return nil, false
+	}
+
+	state, ok := secureStrings.Load(uintptr(unsafe.Pointer(unsafe.StringData(string(s)))))
+	if !ok {
+		return nil, false
+	}
+
+	return state.(*secureString), true
+}
+
+func (s Sensitive) secure() bool {
+	_, ok := s.secureState()
+	return ok
+}
+
+func (s Sensitive) guard() {
+	if state, ok := s.secureState(); ok && state.destroyed.Load() {
+		panic("secret: use of destroyed Sensitive")
+	}
+}
+
+// destroy wipes the buffer backing s in place, the strings reading it see zeros afterwards
+func (s Sensitive) destroy() {
+	if state, ok := s.secureState(); ok && !state.destroyed.Swap(true) {
+		clear(unsafe.Slice(unsafe.StringData(string(s)), state.size))
+	}
+}
+
+// secureSensitive moves every Sensitive value of v into a buffer of newSecureSensitive
+func secureSensitive(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.Type() == sensitiveType && v.CanSet() && !Sensitive(v.String()).secure() {
+			v.SetString(string(newSecureSensitive(v.String())))
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			secureSensitive(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				secureSensitive(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			secureSensitive(v.Index(i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(iter.Value().Type()).Elem()
+			value.Set(iter.Value())
+			secureSensitive(value)
+			v.SetMapIndex(iter.Key(), value)
+		}
+	}
+}
+
+// clone copies a Sensitive loaded WithSecureMemory into a buffer of its own
+func (s Sensitive) clone() Sensitive {
+	if state, ok := s.secureState(); ok && !state.destroyed.Load() {
+		return newSecureSensitive(string(s))
+	}
+
+	return s
+}
+
+func (b *secureBuffer) destroy() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.destroyed {
+		return
+	}
+
+	clear(b.data)
+	b.data = nil
+	b.destroyed = true
+}
+
+func (s SecretBytes) guard() []byte {
+	if s.buf == nil {
+		return nil
+	}
+
+	s.buf.mu.Lock()
+	defer s.buf.mu.Unlock()
+	if s.buf.destroyed {
+		panic("secret: use of destroyed SecretBytes")
+	}
+
+	return s.buf.data
+}
+
+// Bytes returns the buffer itself, slices returned before Destroy read as zeros afterwards
+func (s SecretBytes) Bytes() []byte {
+	return s.guard()
+}
+
+// Reveal returns the value as a string, which like any string can't be wiped
+func (s SecretBytes) Reveal() string {
+	return string(s.guard())
+}
+
+// Len returns the length of the value
+func (s SecretBytes) Len() int {
+	return len(s.guard())
+}
+
+// Locked reports whether the buffer is locked out of swap
+func (s SecretBytes) Locked() bool {
+	return s.buf != nil && s.buf.locked
+}
+
+// Destroyed reports whether Destroy wiped the value
+func (s SecretBytes) Destroyed() bool {
+	if s.buf == nil {
+		return false
+	}
+
+	s.buf.mu.Lock()
+	defer s.buf.mu.Unlock()
+	return s.buf.destroyed
+}
+
+// Destroy zeroes the buffer, it is unlocked and freed once no slice returned by Bytes
+// references it. Copies made by the Cache hold their own buffer
+func (s SecretBytes) Destroy() {
+	if s.buf != nil {
+		s.buf.destroy()
+	}
+}
+
+func (s SecretBytes) clone() SecretBytes {
+	if s.buf == nil || s.Destroyed() {
+		return s
+	}
+
+	return NewSecretBytes(s.guard())
+}
+
+func (s SecretBytes) redacted() string {
+	if s.buf == nil {
+		return ""
+	}
+
+	return RedactedValue
+}
+
+func (s SecretBytes) String() string {
+	return s.redacted()
+}
+
+func (s SecretBytes) GoString() string {
+	return strconv.Quote(s.redacted())
+}
+
+func (s SecretBytes) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'q' || verb == 'v' && f.Flag('#'):
+		fmt.Fprint(f, strconv.Quote(s.redacted()))
+	default:
+		fmt.Fprint(f, s.redacted())
+	}
+}
+
+func (s SecretBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.redacted())
+}
+
+func (s SecretBytes) LogValue() slog.Value {
+	return slog.StringValue(s.redacted())
+}
+
+// UnmarshalJSON decodes a JSON string straight into a secure buffer
+func (s *SecretBytes) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	value, err := unquoteBytes(data)
+	if err != nil {
+		return err
+	}
+
+	s.Destroy()
+	*s = NewSecretBytes(value)
+	clear(value)
+	return nil
+}
+
+// unquoteBytes decodes a JSON string literal into a new byte slice
+func unquoteBytes(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return nil, errors.New("SecretBytes should be a JSON string")
+	}
+
+	data = data[1 : len(data)-1]
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if c != '\\' {
+			out = append(out, c)
+			continue
+		}
+
+		if i++; i >= len(data) {
+			return nil, errors.New("SecretBytes has an unterminated escape")
+		}
+
+		switch data[i] {
+		case '"', '\\', '/':
+			out = append(out, data[i])
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'u':
+			r, n, err := decodeUnicodeEscape(data[i-1:])
+			if err != nil {
+				return nil, err
+			}
+
+			out = utf8.AppendRune(out, r)
+			i += n - 2
+		default:
+			return nil, fmt.Errorf("SecretBytes has an invalid escape \\%c", data[i])
+		}
+	}
+
+	return out, nil
+}
+
+// decodeUnicodeEscape decodes a \uXXXX escape, or a surrogate pair of them, at the start of data
+func decodeUnicodeEscape(data []byte) (rune, int, error) {
+	r, ok := hex4(data)
+	if !ok {
+		return 0, 0, errors.New("SecretBytes has an invalid \\u escape")
+	}
+
+	if utf16.IsSurrogate(r) {
+		if low, ok := hex4(data[6:]); ok {
+			if decoded := utf16.DecodeRune(r, low); decoded != utf8.RuneError {
+				return decoded, 12, nil
+			}
+		}
+
+		return utf8.RuneError, 6, nil
+	}
+
+	return r, 6, nil
+}
+
+func hex4(data []byte) (rune, bool) {
+	if len(data) < 6 || data[0] != '\\' || data[1] != 'u' {
+		return 0, false
+	}
+
+	n, err := strconv.ParseUint(string(data[2:6]), 16, 32)
+	return rune(n), err == nil
+}
+
+var secretBytesType = reflect.TypeOf(SecretBytes{})
+
+// Destroy wipes every SecretBytes of secret and clears its Sensitive values, wiping those
+// loaded WithSecureMemory, other Sensitive strings can't be wiped. The registry of the loader
+// stops scrubbing the values unless the secret was loaded again since
+func Destroy(secret Secret) {
+	v := reflect.ValueOf(secret)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		if found, defaultSecret := findDefaultSecret(v.Elem()); found && defaultSecret.CanAddr() {
+			defaultSecret.Addr().Interface().(*DefaultSecret).untrack()
+		}
+	}
+
+	destroyValue(v)
+}
+
+func destroyValue(v reflect.Value) {
+	if v.Type() == secretBytesType {
+		v.Interface().(SecretBytes).Destroy()
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if v.Type() != sensitiveType {
+			return
+		}
+
+		Sensitive(v.String()).destroy()
+		if v.CanSet() {
+			v.SetString("")
+		}
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			destroyValue(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				destroyValue(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			destroyValue(v.Index(i))
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			destroyValue(iter.Value())
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tokenSecret struct {
+	DefaultSecret
+	Token  SecretBytes `json:"token"`
+	Backup SecretBytes `json:"backup"`
+	Label  Sensitive   `json:"label"`
+}
+
+func (s *tokenSecret) Destroy() {
+	Destroy(s)
+}
+
+func TestSecretBytesDecoding(t *testing.T) {
+	t.Parallel()
+	for raw, expected := range map[string]string{
+		`"plain"`:                 "plain",
+		`"q\"b\\s\/n\nt\t"`:       "q\"b\\s/n\nt\t",
+		`"\u00e9\ud83d\ude00"`:    "é😀",
+		`"unicode é stays as is"`: "unicode é stays as is",
+	} {
+		var s SecretBytes
+		assert.NoError(t, json.Unmarshal([]byte(raw), &s), raw)
+		assert.Equal(t, expected, s.Reveal(), raw)
+		s.Destroy()
+	}
+
+	var s SecretBytes
+	assert.Error(t, json.Unmarshal([]byte(`"bad \x"`), &s))
+	assert.Error(t, json.Unmarshal([]byte(`12`), &s))
+}
+
+func TestSecretBytesRedactedAndDestroyed(t *testing.T) {
+	t.Parallel()
+	s := NewSecretBytes([]byte("hunter2"))
+	assert.Equal(t, 7, s.Len())
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x"} {
+		assert.NotContains(t, fmt.Sprintf(format, s), "hunter2", format)
+	}
+
+	data, err := json.Marshal(s)
+	assert.NoError(t, err)
+	assert.Equal(t, `"******"`, string(data))
+
+	s.Destroy()
+	assert.True(t, s.Destroyed())
+	assert.Panics(t, func() { s.Bytes() })
+	assert.Panics(t, func() { _ = s.Reveal() })
+	assert.NotPanics(t, s.Destroy)
+
+	var empty SecretBytes
+	assert.Nil(t, empty.Bytes())
+	assert.False(t, empty.Destroyed())
+	assert.Equal(t, "", empty.String())
+}
+
+func TestSecretBytesOutlivedByBytes(t *testing.T) {
+	t.Parallel()
+	kept := NewSecretBytes([]byte("kept-value")).Bytes()
+	for i := 0; i < 3; i++ {
+		runtime.GC()
+	}
+
+	assert.Equal(t, "kept-value", string(kept))
+
+	s := NewSecretBytes([]byte("destroyed-value"))
+	retained := s.Bytes()
+	s.Destroy()
+	assert.Equal(t, make([]byte, len(retained)), retained)
+}
+
+func TestDestroySecret(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("token-main/secret.json", []byte(`{"token": "t0ken-value", "label": "visible-label"}`))
+
+	registry := NewRegistry()
+	s := &tokenSecret{}
+	assert.NoError(t, helper.MockLoader(WithRegistry(registry)).Load("token", "main", s))
+	assert.Equal(t, "t0ken-value", s.Token.Reveal())
+	assert.Equal(t, 1, registry.Len())
+
+	content, err := mockFS.ReadFile("token-main/secret.json")
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "t0ken-value")
+
+	Destroy(s)
+	assert.True(t, s.Token.Destroyed())
+	assert.Equal(t, Sensitive(""), s.Label)
+	assert.Panics(t, func() { s.Token.Bytes() })
+
+	assert.Equal(t, 0, registry.Len())
+
+	db := CreateDatabaseSecret("destroy")
+	db.Destroy()
+	assert.Equal(t, "", db.Writer.Params.Password.Reveal())
+	assert.Equal(t, "test_user", db.Writer.Params.Username)
+}
+
+func TestDestroyKeepsReloadedValuesTracked(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	helper.GetMockFileSystem().AddFile("redis-reloaded/secret.json", []byte(`{"master": {"host": "h", "password": "masterpw"}}`))
+	registry := NewRegistry()
+	loader := helper.MockLoader(WithRegistry(registry))
+
+	old, reloaded := &Redis{}, &Redis{}
+	assert.NoError(t, loader.Load("redis", "reloaded", old))
+	assert.NoError(t, loader.Load("redis", "reloaded", reloaded))
+	old.Destroy()
+	assert.Equal(t, 1, registry.Len())
+
+	reloaded.Destroy()
+	assert.Equal(t, 0, registry.Len())
+}
+
+func TestLoadWithSecureMemory(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	helper.GetMockFileSystem().AddFile("redis-secure/secret.json", []byte(`{"master": {"host": "h", "password": "masterpw"}}`))
+	registry := NewRegistry()
+	loader := helper.MockLoader(WithRegistry(registry), WithSecureMemory())
+
+	redis := &Redis{}
+	assert.NoError(t, loader.Load("redis", "secure", redis))
+	assert.Equal(t, "masterpw", redis.Master.Password.Reveal())
+	assert.Equal(t, "masterpw", redis.Slave.Password.Reveal())
+	assert.Equal(t, 0, registry.Len())
+
+	cache := NewCache(loader, 0)
+	cached := &Redis{}
+	assert.NoError(t, cache.Load("redis", "secure", cached))
+	copied := &Redis{}
+	assert.NoError(t, cache.Load("redis", "secure", copied))
+	cached.Destroy()
+	assert.Equal(t, "masterpw", copied.Master.Password.Reveal())
+
+	password := redis.Master.Password
+	redis.Destroy()
+	assert.Equal(t, Sensitive(""), redis.Master.Password)
+	assert.Equal(t, strings.Repeat("\x00", len("masterpw")), string(password))
+	assert.Panics(t, func() { password.Reveal() })
+}
+
+func TestCacheCopiesSecretBytes(t *testing.T) {
+	t.Parallel()
+	fs := NewMockFileSystem()
+	fs.AddFile("token-cached/secret.json", []byte(`{"token": "cached-token"}`))
+	cache := NewCache(NewLoader(WithFileSystem(fs), WithRegistry(nil)), 0)
+
+	first := &tokenSecret{}
+	assert.NoError(t, cache.Load("token", "cached", first))
+	first.Destroy()
+
+	second := &tokenSecret{}
+	assert.NoError(t, cache.Load("token", "cached", second))
+	assert.Equal(t, "cached-token", second.Token.Reveal())
+}
+
+func TestLoadWipesReadBuffers(t *testing.T) {
+	t.Parallel()
+	root := t.TempDir()
+	assert.NoError(t, os.MkdirAll(path.Join(root, "token-wipe"), 0755))
+	assert.NoError(t, os.WriteFile(path.Join(root, "token-wipe", "secret.json"), []byte(`{"token": "wiped-token"}`), 0600))
+
+	documents := newDocumentSet(NewLoader(WithRoot(root)), root)
+	data, err := documents.document("token-wipe")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "wiped-token")
+
+	documents.wipe()
+	assert.Equal(t, make([]byte, len(data)), data)
+}
+
+func TestLoadKeepsFileSystemBuffers(t *testing.T) {
+	t.Parallel()
+	fs := NewMockFileSystem()
+	fs.AddFile("token-kept/secret.json", []byte(`{"token": "kept-token"}`))
+	loader := NewLoader(WithFileSystem(fs), WithRegistry(nil))
+
+	for i := 0; i < 2; i++ {
+		s := &tokenSecret{}
+		assert.NoError(t, loader.Load("token", "kept", s))
+		assert.Equal(t, "kept-token", s.Token.Reveal())
+	}
+}
@@ -0,0 +1,248 @@
+package secret
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoHealthyReplica is returned by Selector.Next when every replica is excluded
+var ErrNoHealthyReplica = errors.New("no healthy replica")
+
+// Candidate is a healthy replica offered to a Strategy
+type Candidate struct {
+	Index   int
+	Weight  uint
+	Latency time.Duration
+	Zone    string
+}
+
+// Strategy chooses one of the healthy candidates, candidates is never empty
+type Strategy interface {
+	Pick(candidates []Candidate) Candidate
+}
+
+// StrategyFunc adapts a function to a Strategy
+type StrategyFunc func(candidates []Candidate) Candidate
+
+func (f StrategyFunc) Pick(candidates []Candidate) Candidate {
+	return f(candidates)
+}
+
+// RoundRobin returns a strategy cycling through the candidates in order
+func RoundRobin() Strategy {
+	var next atomic.Uint64
+	return StrategyFunc(func(candidates []Candidate) Candidate {
+		return candidates[(next.Add(1)-1)%uint64(len(candidates))]
+	})
+}
+
+// Random returns a strategy choosing uniformly at random
+func Random() Strategy {
+	return StrategyFunc(func(candidates []Candidate) Candidate {
+		return candidates[rand.IntN(len(candidates))]
+	})
+}
+
+// Weighted returns a strategy choosing at random in proportion to the candidate weights,
+// a zero weight counts as 1
+func Weighted() Strategy {
+	return StrategyFunc(func(candidates []Candidate) Candidate {
+		var total uint64
+		for _, c := range candidates {
+			total += uint64(max(c.Weight, 1))
+		}
+
+		n := rand.Uint64N(total)
+		for _, c := range candidates {
+			w := uint64(max(c.Weight, 1))
+			if n < w {
+				return c
+			}
+
+			n -= w
+		}
+
+		return candidates[len(candidates)-1]
+	})
+}
+
+// LatencyAware returns a strategy comparing two random candidates and choosing the one with
+// the lower observed latency, unobserved candidates are preferred so they get measured
+func LatencyAware() Strategy {
+	return StrategyFunc(func(candidates []Candidate) Candidate {
+		a := candidates[rand.IntN(len(candidates))]
+		b := candidates[rand.IntN(len(candidates))]
+		if b.Latency < a.Latency {
+			return b
+		}
+
+		return a
+	})
+}
+
+// Nearest returns a strategy cycling through the candidates in zone, or through all
+// candidates when none is in zone
+func Nearest(zone string) Strategy {
+	var next atomic.Uint64
+	return StrategyFunc(func(candidates []Candidate) Candidate {
+		var near []Candidate
+		for _, c := range candidates {
+			if c.Zone == zone {
+				near = append(near, c)
+			}
+		}
+
+		if len(near) == 0 {
+			near = candidates
+		}
+
+		return near[(next.Add(1)-1)%uint64(len(near))]
+	})
+}
+
+// Selector picks replicas with a Strategy, skipping replicas marked down or failing a
+// health check. It is safe for concurrent use
+type Selector[T any] struct {
+	replicas []T
+	strategy Strategy
+	weight   func(T) uint
+	zone     func(T) string
+	checks   []func(T) bool
+
+	mu        sync.RWMutex
+	down      []bool
+	latencies []time.Duration
+}
+
+// SelectorOption configures a Selector
+type SelectorOption[T any] func(*Selector[T])
+
+// WithWeight sets the weight of each replica used by the Weighted strategy
+func WithWeight[T any](weight func(T) uint) SelectorOption[T] {
+	return func(s *Selector[T]) {
+		s.weight = weight
+	}
+}
+
+// WithZone sets the zone of each replica used by the Nearest strategy
+func WithZone[T any](zone func(T) string) SelectorOption[T] {
+	return func(s *Selector[T]) {
+		s.zone = zone
+	}
+}
+
+// WithHealthCheck excludes the replicas check reports unhealthy, it is called on every Next
+// so it should be cheap, e.g. reading a flag maintained by a background prober
+func WithHealthCheck[T any](check func(T) bool) SelectorOption[T] {
+	return func(s *Selector[T]) {
+		s.checks = append(s.checks, check)
+	}
+}
+
+// NewSelector returns a selector over replicas, RoundRobin is used when strategy is nil
+func NewSelector[T any](replicas []T, strategy Strategy, opts ...SelectorOption[T]) *Selector[T] {
+	if strategy == nil {
+		strategy = RoundRobin()
+	}
+
+	s := &Selector[T]{
+		replicas:  replicas,
+		strategy:  strategy,
+		down:      make([]bool, len(replicas)),
+		latencies: make([]time.Duration, len(replicas)),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Len returns the number of replicas, healthy or not
+func (s *Selector[T]) Len() int {
+	return len(s.replicas)
+}
+
+// Replica returns the replica at index i
+func (s *Selector[T]) Replica(i int) T {
+	return s.replicas[i]
+}
+
+// Next returns the index and value of the chosen healthy replica
+func (s *Selector[T]) Next() (int, T, error) {
+	candidates := s.candidates()
+	if len(candidates) == 0 {
+		var zero T
+		return -1, zero, ErrNoHealthyReplica
+	}
+
+	i := s.strategy.Pick(candidates).Index
+	return i, s.replicas[i], nil
+}
+
+func (s *Selector[T]) candidates() []Candidate {
+	s.mu.RLock()
+	down := append([]bool(nil), s.down...)
+	latencies := append([]time.Duration(nil), s.latencies...)
+	s.mu.RUnlock()
+
+	candidates := make([]Candidate, 0, len(s.replicas))
+	for i, replica := range s.replicas {
+		if down[i] || !s.healthy(replica) {
+			continue
+		}
+
+		c := Candidate{Index: i, Latency: latencies[i]}
+		if s.weight != nil {
+			c.Weight = s.weight(replica)
+		}
+
+		if s.zone != nil {
+			c.Zone = s.zone(replica)
+		}
+
+		candidates = append(candidates, c)
+	}
+
+	return candidates
+}
+
+func (s *Selector[T]) healthy(replica T) bool {
+	for _, check := range s.checks {
+		if !check(replica) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MarkDown excludes replica i until MarkUp
+func (s *Selector[T]) MarkDown(i int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down[i] = true
+}
+
+// MarkUp includes replica i again
+func (s *Selector[T]) MarkUp(i int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down[i] = false
+}
+
+// Observe records a latency of replica i for the LatencyAware strategy, it is kept as an
+// exponentially weighted moving average
+func (s *Selector[T]) Observe(i int, latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.latencies[i] == 0 {
+		s.latencies[i] = latency
+		return
+	}
+
+	s.latencies[i] = (s.latencies[i]*7 + latency*3) / 10
+}
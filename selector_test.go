@@ -0,0 +1,153 @@
+package secret
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pickCounts(s *Selector[string], n int) map[string]int {
+	counts := map[string]int{}
+	for i := 0; i < n; i++ {
+		_, replica, err := s.Next()
+		if err != nil {
+			return nil
+		}
+
+		counts[replica]++
+	}
+
+	return counts
+}
+
+func TestSelectorStrategies(t *testing.T) {
+	t.Parallel()
+	replicas := []string{"a", "b", "c"}
+
+	roundRobin := NewSelector(replicas, nil)
+	var order []string
+	for i := 0; i < 4; i++ {
+		_, replica, err := roundRobin.Next()
+		assert.NoError(t, err)
+		order = append(order, replica)
+	}
+
+	assert.Equal(t, []string{"a", "b", "c", "a"}, order)
+	assert.Len(t, pickCounts(NewSelector(replicas, Random()), 300), 3)
+
+	weights := map[string]uint{"a": 0, "b": 0, "c": 98}
+	weighted := pickCounts(NewSelector(replicas, Weighted(), WithWeight(func(r string) uint { return weights[r] })), 1000)
+	assert.Greater(t, weighted["c"], 900)
+
+	latencyAware := NewSelector(replicas, LatencyAware())
+	latencyAware.Observe(0, 50*time.Millisecond)
+	latencyAware.Observe(1, 40*time.Millisecond)
+	latencyAware.Observe(2, time.Millisecond)
+	counts := pickCounts(latencyAware, 900)
+	assert.Greater(t, counts["c"], counts["a"]+counts["b"])
+}
+
+func TestSelectorExclusion(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	unhealthy := map[string]bool{"b": true}
+	s := NewSelector([]string{"a", "b", "c"}, nil, WithHealthCheck(func(r string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return !unhealthy[r]
+	}))
+
+	assert.Equal(t, map[string]int{"a": 5, "c": 5}, pickCounts(s, 10))
+
+	s.MarkDown(0)
+	assert.Equal(t, map[string]int{"c": 4}, pickCounts(s, 4))
+
+	s.MarkDown(2)
+	i, replica, err := s.Next()
+	assert.Equal(t, ErrNoHealthyReplica, err)
+	assert.Equal(t, -1, i)
+	assert.Empty(t, replica)
+
+	s.MarkUp(0)
+	assert.Equal(t, map[string]int{"a": 2}, pickCounts(s, 2))
+}
+
+func TestSelectorObserveAverages(t *testing.T) {
+	t.Parallel()
+	s := NewSelector([]string{"a"}, nil)
+	s.Observe(0, 100*time.Millisecond)
+	s.Observe(0, 200*time.Millisecond)
+	assert.Equal(t, 130*time.Millisecond, s.candidates()[0].Latency)
+	assert.Equal(t, 1, s.Len())
+	assert.Equal(t, "a", s.Replica(0))
+}
+
+func TestDatabaseReplicas(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("database-readers/secret.json", []byte(`{
+		"writer": {"adapter": "mysql", "params": {"host": "writer"}},
+		"readers": [
+			{"adapter": "mysql", "weight": 3, "params": {"host": "replica-1"}},
+			{"adapter": "mysql", "params": {"host": "replica-2"}}
+		]
+	}`))
+	mockFS.AddFile("database-both/secret.json", []byte(`{
+		"writer": {"adapter": "mysql", "params": {"host": "writer"}},
+		"reader": {"adapter": "mysql", "params": {"host": "reader"}},
+		"readers": [{"adapter": "mysql", "params": {"host": "replica-1"}}]
+	}`))
+	mockFS.AddFile("database-invalid/secret.json", []byte(`{
+		"writer": {"adapter": "mysql", "params": {"host": "writer"}},
+		"readers": [{"adapter": "mysql", "params": {"host": "replica-1"}}, {"adapter": "mysql", "params": {"dbname": "app"}}]
+	}`))
+
+	hosts := func(metas []DatabaseMeta) []string {
+		var out []string
+		for _, meta := range metas {
+			out = append(out, meta.Params.Host)
+		}
+
+		return out
+	}
+
+	db := &Database{}
+	assert.NoError(t, helper.MockLoader().Load("database", "readers", db))
+	assert.Equal(t, []string{"replica-1", "replica-2"}, hosts(db.Replicas()))
+	assert.Equal(t, uint(3306), db.Readers[1].Params.Port)
+
+	selector := db.ReaderSelector(nil)
+	_, first, err := selector.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "replica-1", first.Params.Host)
+	assert.Equal(t, uint(3), first.Weight)
+
+	db = &Database{}
+	assert.NoError(t, helper.MockLoader().Load("database", "both", db))
+	assert.Equal(t, []string{"reader", "replica-1"}, hosts(db.Replicas()))
+
+	assert.Equal(t, []string{"writer"}, hosts((&Database{Writer: db.Writer}).Replicas()))
+
+	err = helper.MockLoader().Load("database", "invalid", &Database{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "readers[1].params.host is required")
+}
+
+func TestSelectorNearest(t *testing.T) {
+	t.Parallel()
+	zones := map[string]string{"a1": "a", "b1": "b", "b2": "b"}
+	zone := WithZone(func(r string) string { return zones[r] })
+
+	near := NewSelector([]string{"a1", "b1", "b2"}, Nearest("b"), zone)
+	assert.Equal(t, map[string]int{"b1": 2, "b2": 2}, pickCounts(near, 4))
+
+	near.MarkDown(1)
+	near.MarkDown(2)
+	assert.Equal(t, map[string]int{"a1": 2}, pickCounts(near, 2))
+
+	elsewhere := NewSelector([]string{"a1", "b1"}, Nearest("c"), zone)
+	assert.Equal(t, map[string]int{"a1": 1, "b1": 1}, pickCounts(elsewhere, 2))
+}
@@ -0,0 +1,202 @@
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// RedactedValue replaces sensitive values in formatted, logged and marshalled output
+const RedactedValue = "******"
+
+// Sensitive is a string that is redacted whenever it is formatted, logged or marshalled,
+// it decodes from JSON like a plain string and Reveal returns the raw value
+type Sensitive string
+
+// Reveal returns the raw value, it panics when the value was loaded WithSecureMemory and
+// its secret destroyed
+func (s Sensitive) Reveal() string {
+	s.guard()
+	return string(s)
+}
+
+func (s Sensitive) redacted() string {
+	if s == "" {
+		return ""
+	}
+
+	return RedactedValue
+}
+
+func (s Sensitive) String() string {
+	return s.redacted()
+}
+
+func (s Sensitive) GoString() string {
+	return strconv.Quote(s.redacted())
+}
+
+func (s Sensitive) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 'q' || verb == 'v' && f.Flag('#'):
+		fmt.Fprint(f, strconv.Quote(s.redacted()))
+	default:
+		fmt.Fprint(f, s.redacted())
+	}
+}
+
+func (s Sensitive) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.redacted())
+}
+
+func (s Sensitive) LogValue() slog.Value {
+	return slog.StringValue(s.redacted())
+}
+
+var sensitiveType = reflect.TypeOf(Sensitive(""))
+
+// marshalRevealed marshals v like json.Marshal but with Sensitive values revealed,
+// for code writing secret documents
+func marshalRevealed(v interface{}) ([]byte, error) {
+	return json.Marshal(revealed(reflect.ValueOf(v)))
+}
+
+func revealed(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if v.Type() == sensitiveType {
+		return v.String()
+	}
+
+	if v.Type() == secretBytesType {
+		return v.Interface().(SecretBytes).Reveal()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		return revealed(v.Elem())
+	case reflect.Struct:
+		if v.Type().Implements(jsonMarshalerType) {
+			return v.Interface()
+		}
+
+		out := make(map[string]interface{})
+		revealedFields(v, out)
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+
+		fallthrough
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+
+		out := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out[i] = revealed(v.Index(i))
+		}
+
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = revealed(iter.Value())
+		}
+
+		return out
+	}
+
+	return v.Interface()
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+func revealedFields(v reflect.Value, out map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && tag == "" {
+			revealedFields(v.Field(i), out)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if strings.Contains(tag, ",omitempty") && v.Field(i).IsZero() {
+			continue
+		}
+
+		out[fieldName(field)] = revealed(v.Field(i))
+	}
+}
+
+// secretLogValue logs a secret as a group of its name, path and fields by json name,
+// Sensitive values log themselves redacted
+func secretLogValue(v reflect.Value) slog.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return slog.AnyValue(nil)
+		}
+
+		v = v.Elem()
+	}
+
+	var attrs []slog.Attr
+	if secret, ok := v.Addr().Interface().(Secret); ok {
+		attrs = append(attrs, slog.String("name", secret.Name()), slog.String("path", secret.Path()))
+	}
+
+	return slog.GroupValue(append(attrs, logAttrs(v)...)...)
+}
+
+func logAttrs(v reflect.Value) []slog.Attr {
+	var attrs []slog.Attr
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		fieldValue := v.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			attrs = append(attrs, logAttrs(fieldValue)...)
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Struct && !fieldValue.Type().Implements(logValuerType) {
+			attrs = append(attrs, slog.Attr{Key: fieldName(field), Value: slog.GroupValue(logAttrs(fieldValue)...)})
+			continue
+		}
+
+		attrs = append(attrs, slog.Any(fieldName(field), fieldValue.Interface()))
+	}
+
+	return attrs
+}
+
+var logValuerType = reflect.TypeOf((*slog.LogValuer)(nil)).Elem()
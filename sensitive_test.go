@@ -0,0 +1,79 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensitiveFormatting(t *testing.T) {
+	t.Parallel()
+	s := Sensitive("hunter2")
+	assert.Equal(t, "hunter2", s.Reveal())
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%10s"} {
+		assert.NotContains(t, fmt.Sprintf(format, s), "hunter2", format)
+	}
+
+	assert.Equal(t, RedactedValue, s.String())
+	assert.Equal(t, `"******"`, fmt.Sprintf("%#v", s))
+	assert.Equal(t, "", Sensitive("").String())
+
+	data, err := json.Marshal(s)
+	assert.NoError(t, err)
+	assert.Equal(t, `"******"`, string(data))
+
+	var decoded Sensitive
+	assert.NoError(t, json.Unmarshal([]byte(`"raw"`), &decoded))
+	assert.Equal(t, "raw", decoded.Reveal())
+}
+
+func TestBuiltinSecretsRedacted(t *testing.T) {
+	t.Parallel()
+	db := CreateDatabaseSecret("redacted")
+	c := CreateCassandraSecret("redacted")
+
+	for _, v := range []interface{}{db, *db, c, *c} {
+		for _, format := range []string{"%v", "%+v", "%#v"} {
+			out := fmt.Sprintf(format, v)
+			assert.NotContains(t, out, "_password", format)
+			assert.Contains(t, out, RedactedValue, format)
+		}
+
+		data, err := json.Marshal(v)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "_password")
+	}
+}
+
+func TestBuiltinSecretsLogValue(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Info("loaded", "db", CreateDatabaseSecret("logged"), "redis", CreateRedisSecret("logged"))
+	assert.NotContains(t, buf.String(), "test_password")
+	assert.Contains(t, buf.String(), `"db":{"name":"logged","path":"database-logged/secret.json","writer":{"adapter":"mysql"`)
+	assert.Contains(t, buf.String(), `"password":"******"`)
+	assert.Contains(t, buf.String(), `"redis":{"name":"logged"`)
+
+	buf.Reset()
+	logger = slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Info("loaded", "cassandra", CreateCassandraSecret("logged"))
+	assert.NotContains(t, buf.String(), "writer_password")
+	assert.Contains(t, buf.String(), "cassandra.writer.password=******")
+}
+
+func TestHelpersWriteRevealedValues(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	assert.NoError(t, helper.CreateMockSecretFile("database", "revealed", CreateDatabaseSecret("revealed")))
+
+	db := &Database{}
+	assert.NoError(t, helper.MockLoader().Load("database", "revealed", db))
+	assert.Equal(t, "test_password", db.Writer.Params.Password.Reveal())
+	assert.Equal(t, "readonly_password", db.Reader.Params.Password.Reveal())
+}
@@ -0,0 +1,169 @@
+// Package sqlopen opens database/sql connection pools from Database secrets, it
+// lives outside the secret package so the core stays free of database/sql.
+//
+// Drivers are not imported here, the application imports the driver it uses and
+// the adapter of the secret selects it by its registered driver name:
+//
+//	import _ "github.com/go-sql-driver/mysql"
+//
+//	db, err := sqlopen.OpenWriter(database, sqlopen.WithMaxOpenConns(20))
+package sqlopen
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	secret "github.com/yetiz-org/goth-secret"
+)
+
+var driversMu sync.RWMutex
+
+var drivers = map[string]string{
+	"mysql":      "mysql",
+	"postgres":   "postgres",
+	"postgresql": "postgres",
+	"sqlserver":  "sqlserver",
+	"mssql":      "sqlserver",
+	"sqlite":     "sqlite3",
+	"sqlite3":    "sqlite3",
+}
+
+// Register sets the database/sql driver name opened for adapter, e.g. "pgx" for postgres
+func Register(adapter string, driverName string) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	drivers[adapter] = driverName
+}
+
+// Driver returns the database/sql driver name registered for adapter
+func Driver(adapter string) (string, bool) {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	driverName, ok := drivers[adapter]
+	return driverName, ok
+}
+
+type config struct {
+	maxOpenConns    int
+	maxIdleConns    int
+	connMaxLifetime time.Duration
+	connMaxIdleTime time.Duration
+}
+
+// Option configures the pool of the opened *sql.DB, options override the pool settings
+// of the DatabaseMeta params
+type Option func(*config)
+
+// WithMaxOpenConns sets the maximum number of open connections, see sql.DB.SetMaxOpenConns
+func WithMaxOpenConns(n int) Option {
+	return func(c *config) {
+		c.maxOpenConns = n
+	}
+}
+
+// WithMaxIdleConns sets the maximum number of idle connections, see sql.DB.SetMaxIdleConns
+func WithMaxIdleConns(n int) Option {
+	return func(c *config) {
+		c.maxIdleConns = n
+	}
+}
+
+// WithConnMaxLifetime sets how long a connection may be reused, see sql.DB.SetConnMaxLifetime
+func WithConnMaxLifetime(d time.Duration) Option {
+	return func(c *config) {
+		c.connMaxLifetime = d
+	}
+}
+
+// WithConnMaxIdleTime sets how long a connection may stay idle, see sql.DB.SetConnMaxIdleTime
+func WithConnMaxIdleTime(d time.Duration) Option {
+	return func(c *config) {
+		c.connMaxIdleTime = d
+	}
+}
+
+// Open opens a pool for meta with the driver registered for its adapter, like sql.Open
+// it does not connect, call Ping to verify the connection
+func Open(meta *secret.DatabaseMeta, opts ...Option) (*sql.DB, error) {
+	driverName, ok := Driver(meta.Adapter)
+	if !ok {
+		return nil, fmt.Errorf("sqlopen: no driver registered for adapter %q", meta.Adapter)
+	}
+
+	dsn, err := meta.DSN()
+	if err != nil {
+		return nil, fmt.Errorf("sqlopen: %w", err)
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sqlopen: %w", err)
+	}
+
+	c := &config{
+		maxOpenConns:    meta.Params.MaxOpenConns,
+		maxIdleConns:    meta.Params.MaxIdleConns,
+		connMaxLifetime: meta.Params.ConnMaxLifetime.Duration(),
+		connMaxIdleTime: meta.Params.ConnMaxIdleTime.Duration(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.maxOpenConns != 0 {
+		db.SetMaxOpenConns(c.maxOpenConns)
+	}
+
+	if c.maxIdleConns != 0 {
+		db.SetMaxIdleConns(c.maxIdleConns)
+	}
+
+	if c.connMaxLifetime != 0 {
+		db.SetConnMaxLifetime(c.connMaxLifetime)
+	}
+
+	if c.connMaxIdleTime != 0 {
+		db.SetConnMaxIdleTime(c.connMaxIdleTime)
+	}
+
+	return db, nil
+}
+
+// OpenWriter opens a pool for the writer of database
+func OpenWriter(database *secret.Database, opts ...Option) (*sql.DB, error) {
+	return Open(&database.Writer, opts...)
+}
+
+// OpenReader opens a pool for the first replica of database, see Database.Replicas, falling
+// back to the writer when no reader is configured
+func OpenReader(database *secret.Database, opts ...Option) (*sql.DB, error) {
+	replicas := database.Replicas()
+	if replicas[0].Adapter == "" {
+		return OpenWriter(database, opts...)
+	}
+
+	return Open(&replicas[0], opts...)
+}
+
+// OpenReaders opens a pool for every replica of database, in the order of Database.Replicas,
+// to be used with Database.ReaderSelector
+func OpenReaders(database *secret.Database, opts ...Option) ([]*sql.DB, error) {
+	replicas := database.Replicas()
+	dbs := make([]*sql.DB, 0, len(replicas))
+	for i := range replicas {
+		db, err := Open(&replicas[i], opts...)
+		if err != nil {
+			for _, opened := range dbs {
+				opened.Close()
+			}
+
+			return nil, err
+		}
+
+		dbs = append(dbs, db)
+	}
+
+	return dbs, nil
+}
@@ -0,0 +1,165 @@
+package sqlopen
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	secret "github.com/yetiz-org/goth-secret"
+)
+
+// fakeDriver records the DSNs it is opened with
+type fakeDriver struct {
+	mu   sync.Mutex
+	dsns []string
+}
+
+func (d *fakeDriver) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dsns = append(d.dsns, dsn)
+	return fakeConn{}, nil
+}
+
+func (d *fakeDriver) opened() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.dsns...)
+}
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (fakeConn) Close() error                        { return nil }
+func (fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+var fake = &fakeDriver{}
+
+func init() {
+	sql.Register("sqlopen-fake", fake)
+}
+
+func useFakeDriver(t *testing.T, adapters ...string) {
+	for _, adapter := range adapters {
+		previous, _ := Driver(adapter)
+		Register(adapter, "sqlopen-fake")
+		t.Cleanup(func() { Register(adapter, previous) })
+	}
+}
+
+func database() *secret.Database {
+	db := &secret.Database{}
+	db.Writer.Adapter = "mysql"
+	db.Writer.Params.Host = "writer.local"
+	db.Writer.Params.Port = 3306
+	db.Writer.Params.DBName = "app"
+	db.Writer.Params.Username = "user"
+	db.Writer.Params.Password = "pass"
+	return db
+}
+
+func TestOpenWriterAndReader(t *testing.T) {
+	useFakeDriver(t, "mysql", "postgres")
+	db := database()
+	db.Reader.Adapter = "postgres"
+	db.Reader.Params.Host = "reader.local"
+	db.Reader.Params.Port = 5432
+
+	writer, err := OpenWriter(db, WithMaxOpenConns(7), WithMaxIdleConns(3), WithConnMaxLifetime(time.Minute))
+	assert.NoError(t, err)
+	defer writer.Close()
+	assert.NoError(t, writer.PingContext(context.Background()))
+	assert.Equal(t, 7, writer.Stats().MaxOpenConnections)
+
+	reader, err := OpenReader(db)
+	assert.NoError(t, err)
+	defer reader.Close()
+	assert.NoError(t, reader.Ping())
+
+	assert.Contains(t, fake.opened(), "user:pass@tcp(writer.local:3306)/app")
+	assert.Contains(t, fake.opened(), "host=reader.local port=5432")
+}
+
+func TestOpenReaderFallsBackToWriter(t *testing.T) {
+	useFakeDriver(t, "mysql")
+	db := database()
+	db.Writer.Params.DBName = "fallback"
+
+	reader, err := OpenReader(db)
+	assert.NoError(t, err)
+	defer reader.Close()
+	assert.NoError(t, reader.Ping())
+	assert.Contains(t, fake.opened(), "user:pass@tcp(writer.local:3306)/fallback")
+}
+
+func TestOpenErrors(t *testing.T) {
+	db := database()
+	db.Writer.Adapter = "oracle"
+	_, err := OpenWriter(db)
+	assert.EqualError(t, err, `sqlopen: no driver registered for adapter "oracle"`)
+
+	Register("oracle", "sqlopen-fake")
+	defer func() {
+		driversMu.Lock()
+		delete(drivers, "oracle")
+		driversMu.Unlock()
+	}()
+
+	_, err = OpenWriter(db)
+	assert.EqualError(t, err, `sqlopen: no DSN builder registered for adapter "oracle"`)
+
+	db.Writer.Adapter = "sqlite"
+	Register("sqlite", "sqlopen-missing")
+	defer Register("sqlite", "sqlite3")
+	db.Writer.Params.DBName = "app.db"
+	_, err = OpenWriter(db)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown driver "sqlopen-missing"`)
+}
+
+func TestOpenAppliesMetaPoolSettings(t *testing.T) {
+	useFakeDriver(t, "mysql")
+	db := database()
+	db.Writer.Params.MaxOpenConns = 5
+	db.Writer.Params.ConnMaxLifetime = secret.Duration(time.Minute)
+
+	writer, err := OpenWriter(db)
+	assert.NoError(t, err)
+	defer writer.Close()
+	assert.Equal(t, 5, writer.Stats().MaxOpenConnections)
+
+	overridden, err := OpenWriter(db, WithMaxOpenConns(9))
+	assert.NoError(t, err)
+	defer overridden.Close()
+	assert.Equal(t, 9, overridden.Stats().MaxOpenConnections)
+}
+
+func TestOpenReaders(t *testing.T) {
+	useFakeDriver(t, "mysql")
+	db := database()
+	for _, host := range []string{"replica-1", "replica-2"} {
+		replica := db.Writer
+		replica.Params.Host = host
+		db.Readers = append(db.Readers, replica)
+	}
+
+	readers, err := OpenReaders(db)
+	assert.NoError(t, err)
+	assert.Len(t, readers, 2)
+	for _, reader := range readers {
+		assert.NoError(t, reader.Ping())
+		reader.Close()
+	}
+
+	assert.Contains(t, fake.opened(), "user:pass@tcp(replica-1:3306)/app")
+	assert.Contains(t, fake.opened(), "user:pass@tcp(replica-2:3306)/app")
+
+	db.Readers[1].Adapter = "oracle"
+	_, err = OpenReaders(db)
+	assert.EqualError(t, err, `sqlopen: no driver registered for adapter "oracle"`)
+}
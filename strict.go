@@ -0,0 +1,168 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// strictScanner walks the tokens of a document alongside the type it decodes into,
+// reporting what encoding/json silently accepts: unknown keys, keys matching a field
+// only case-insensitively and, when duplicates is set, keys repeated within an object
+type strictScanner struct {
+	dec        *json.Decoder
+	duplicates bool
+	errs       *ValidationError
+}
+
+// checkStrictKeys reports the unknown and mismatched keys of data decoded into t
+func checkStrictKeys(data []byte, t reflect.Type) error {
+	s := &strictScanner{dec: json.NewDecoder(bytes.NewReader(data)), errs: &ValidationError{}}
+	if err := s.value(t, ""); err != nil {
+		return err
+	}
+
+	return s.errs.Err()
+}
+
+// checkDuplicateKeys reports keys repeated within an object of data
+func checkDuplicateKeys(data []byte) error {
+	s := &strictScanner{dec: json.NewDecoder(bytes.NewReader(data)), duplicates: true, errs: &ValidationError{}}
+	if err := s.value(nil, ""); err != nil {
+		return err
+	}
+
+	return s.errs.Err()
+}
+
+func (s *strictScanner) value(t reflect.Type, fieldPath string) error {
+	token, err := s.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		return s.object(t, fieldPath)
+	case json.Delim('['):
+		return s.array(t, fieldPath)
+	}
+
+	return nil
+}
+
+func (s *strictScanner) object(t reflect.Type, fieldPath string) error {
+	t = strictType(t)
+	var fields map[string]reflect.Type
+	if t != nil && t.Kind() == reflect.Struct {
+		fields = jsonFields(t)
+	}
+
+	seen := make(map[string]bool)
+	for s.dec.More() {
+		token, err := s.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key := token.(string)
+		keyPath := joinFieldPath(fieldPath, key)
+		if s.duplicates && seen[key] {
+			s.errs.Add(keyPath, "duplicate", "is a duplicate key")
+		}
+
+		seen[key] = true
+		var fieldType reflect.Type
+		switch {
+		case fields != nil:
+			if ft, ok := fields[key]; ok {
+				fieldType = ft
+			} else if name, ok := foldField(fields, key); ok {
+				s.errs.Add(keyPath, "case", "does not match the case of field %q", name)
+			} else {
+				s.errs.Add(keyPath, "unknown", "is an unknown field")
+			}
+		case t != nil && t.Kind() == reflect.Map:
+			fieldType = t.Elem()
+		}
+
+		if err := s.value(fieldType, keyPath); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.dec.Token()
+	return err
+}
+
+func (s *strictScanner) array(t reflect.Type, fieldPath string) error {
+	t = strictType(t)
+	var elemType reflect.Type
+	if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		elemType = t.Elem()
+	}
+
+	for i := 0; s.dec.More(); i++ {
+		if err := s.value(elemType, fmt.Sprintf("%s[%d]", fieldPath, i)); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.dec.Token()
+	return err
+}
+
+// strictType dereferences t, types decoding themselves are left unchecked
+func strictType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return nil
+	}
+
+	return t
+}
+
+// jsonFields returns the fields of struct t by their exact json name, promoting embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && tag == "" {
+			for name, ft := range jsonFields(field.Type) {
+				if _, ok := fields[name]; !ok {
+					fields[name] = ft
+				}
+			}
+
+			continue
+		}
+
+		if field.IsExported() {
+			fields[fieldName(field)] = field.Type
+		}
+	}
+
+	return fields
+}
+
+func foldField(fields map[string]reflect.Type, key string) (string, bool) {
+	for name := range fields {
+		if strings.EqualFold(name, key) {
+			return name, true
+		}
+	}
+
+	return "", false
+}
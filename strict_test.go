@@ -0,0 +1,67 @@
+package secret
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictDecoding(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("cassandra-typo/secret.json", []byte(`{
+		"writer": {"endpoints": ["w:9042"], "usrname": "user", "Keyspace": "ks", "password": "a", "password": "b"},
+		"readers": [{"endpoints": ["r:9042"]}]
+	}`))
+	mockFS.AddFile("cassandra-valid/secret.json", []byte(`{"writer": {"endpoints": ["w:9042"], "username": "user"}}`))
+
+	lenient := &Cassandra{}
+	assert.NoError(t, helper.MockLoader().Load("cassandra", "typo", lenient))
+	assert.Equal(t, "", lenient.Writer.Username)
+
+	err := helper.MockLoader(WithStrictDecoding()).Load("cassandra", "typo", &Cassandra{})
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "writer.password", validationErr.Fields[0].Path)
+	assert.Equal(t, "duplicate", validationErr.Fields[0].Rule)
+
+	mockFS.AddFile("cassandra-typo/secret.json", []byte(`{
+		"writer": {"endpoints": ["w:9042"], "usrname": "user", "Keyspace": "ks"},
+		"readers": [{"endpoints": ["r:9042"]}]
+	}`))
+	err = helper.MockLoader(WithStrictDecoding()).Load("cassandra", "typo", &Cassandra{})
+	assert.True(t, errors.As(err, &validationErr))
+
+	paths := make([]string, 0)
+	for _, field := range validationErr.Fields {
+		paths = append(paths, field.Path+":"+field.Rule)
+	}
+
+	assert.Equal(t, []string{"writer.usrname:unknown", "writer.Keyspace:case", "readers:unknown"}, paths)
+	assert.Contains(t, err.Error(), `writer.Keyspace does not match the case of field "keyspace"`)
+
+	c := &Cassandra{}
+	assert.NoError(t, helper.MockLoader(WithStrictDecoding()).Load("cassandra", "valid", c))
+	assert.Equal(t, "user", c.Writer.Username)
+}
+
+func TestStrictDecodingChecksOverlays(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("redis-main/secret.json", []byte(`{"master": {"host": "dev"}}`))
+	mockFS.AddFile("redis-main/secret.prod.json", []byte(`{"master": {"host": "a", "host": "b"}, "slave": {"hots": "s"}}`))
+
+	err := helper.MockLoader(WithStrictDecoding(), WithProfile("prod")).Load("redis", "main", &Redis{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "redis-main/secret.prod.json: secret validation failed: master.host is a duplicate key")
+
+	mockFS.AddFile("redis-main/secret.prod.json", []byte(`{"slave": {"hots": "s"}}`))
+	err = helper.MockLoader(WithStrictDecoding(), WithProfile("prod")).Load("redis", "main", &Redis{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "slave.hots is an unknown field")
+
+	assert.NoError(t, helper.MockLoader(WithStrict(), WithProfile("prod")).Load("redis", "main", &Redis{}))
+}
@@ -1,12 +1,19 @@
 package secret
 
 import (
-	"encoding/json"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"fmt"
 	"io/fs"
+	"math/big"
 	"os"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -14,6 +21,7 @@ import (
 // TestHelper provides utilities for testing secret loading functionality
 type TestHelper struct {
 	originalPath string
+	root         string
 	tempDir      string
 	mockFS       *MockFileSystem
 	mockEnv      *MockEnvironment
@@ -28,6 +36,8 @@ func NewTestHelper() *TestHelper {
 }
 
 // SetupTempDirectory creates a temporary directory for testing and sets the secret path
+//
+// Deprecated: it swaps the global PATH and is unsafe with t.Parallel, use SetupLoader instead
 func (th *TestHelper) SetupTempDirectory(t *testing.T, tempPath string) func() {
 	th.originalPath = PATH
 	PATH = tempPath
@@ -40,9 +50,29 @@ func (th *TestHelper) SetupTempDirectory(t *testing.T, tempPath string) func() {
 	}
 }
 
-// CreateSecretFile creates a secret file with the given content in the temporary directory
+// SetupLoader creates a per-test temporary directory and returns a Loader rooted there,
+// secret files created afterwards are written into it and removed with the test
+func (th *TestHelper) SetupLoader(t *testing.T, opts ...LoaderOption) *Loader {
+	th.root = t.TempDir()
+	return NewLoader(append([]LoaderOption{WithRoot(th.root)}, opts...)...)
+}
+
+// MockLoader returns a Loader reading from the mock file system, the sensitive values it loads
+// are tracked in a registry of its own rather than DefaultRegistry
+func (th *TestHelper) MockLoader(opts ...LoaderOption) *Loader {
+	defaults := []LoaderOption{WithFileSystem(th.mockFS), WithEnvironment(th.mockEnv), WithRegistry(NewRegistry())}
+	return NewLoader(append(defaults, opts...)...)
+}
+
+// CreateSecretFile creates a secret file with the given content in the temporary directory,
+// Sensitive values are written unredacted
 func (th *TestHelper) CreateSecretFile(t *testing.T, typ, name string, content interface{}) (string, func()) {
-	testDir := path.Join(".", fmt.Sprintf("%s-%s", typ, name))
+	root := th.root
+	if root == "" {
+		root = "."
+	}
+
+	testDir := path.Join(root, fmt.Sprintf("%s-%s", typ, name))
 	err := os.MkdirAll(testDir, fs.ModePerm)
 	assert.NoError(t, err)
 	
@@ -52,7 +82,7 @@ func (th *TestHelper) CreateSecretFile(t *testing.T, typ, name string, content i
 	if str, ok := content.(string); ok {
 		jsonData = []byte(str)
 	} else {
-		jsonData, err = json.Marshal(content)
+		jsonData, err = marshalRevealed(content)
 		assert.NoError(t, err)
 	}
 	
@@ -175,7 +205,10 @@ func CreateRedisSecret(name string) *Redis {
 	return redis
 }
 
-// CreateCassandraSecret creates a Cassandra secret with default values for testing
+// CreateCassandraSecret creates a Cassandra secret with default values for testing.
+// It sets no ca_path: Load rejects a ca_path that is not an existing PEM file, which the
+// /path/to/*/ca.pem placeholders set before validation existed are not, use
+// CreateTestCertificates for a Cassandra secret with a CA
 func CreateCassandraSecret(name string) *Cassandra {
 	cassandra := &Cassandra{}
 	cassandra.DefaultSecret._Name = name
@@ -186,14 +219,12 @@ func CreateCassandraSecret(name string) *Cassandra {
 	cassandra.Writer.Keyspace = "test_keyspace"
 	cassandra.Writer.Username = "writer_user"
 	cassandra.Writer.Password = "writer_password"
-	cassandra.Writer.CaPath = "/path/to/writer/ca.pem"
 	
 	// Set default reader configuration
 	cassandra.Reader.Endpoints = []string{"readonly.localhost:9042"}
 	cassandra.Reader.Keyspace = "test_keyspace"
 	cassandra.Reader.Username = "reader_user"
 	cassandra.Reader.Password = "reader_password"
-	cassandra.Reader.CaPath = "/path/to/reader/ca.pem"
 	
 	return cassandra
 }
@@ -302,3 +333,73 @@ func (bh *BenchmarkHelper) SetupBenchmarkData() {
 func (bh *BenchmarkHelper) GetTestData(dataType string) interface{} {
 	return bh.testData[dataType]
 }
+
+// TestCertificates holds PEM files of a CA and a client certificate signed by it
+type TestCertificates struct {
+	CAPath   string
+	CertPath string
+	KeyPath  string
+	CAPEM    []byte
+	CertPEM  []byte
+	KeyPEM   []byte
+}
+
+// CreateTestCertificates writes a self-signed CA and a localhost certificate signed by it into dir
+func CreateTestCertificates(t *testing.T, dir string) *TestCertificates {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "goth-secret test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	assert.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	certTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, certTemplate, caTemplate, &key.PublicKey, caKey)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	certs := &TestCertificates{
+		CAPath:   path.Join(dir, "ca.pem"),
+		CertPath: path.Join(dir, "cert.pem"),
+		KeyPath:  path.Join(dir, "key.pem"),
+		CAPEM:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		CertPEM:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		KeyPEM:   pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	}
+
+	assert.NoError(t, os.MkdirAll(dir, fs.ModePerm))
+	assert.NoError(t, os.WriteFile(certs.CAPath, certs.CAPEM, 0600))
+	assert.NoError(t, os.WriteFile(certs.CertPath, certs.CertPEM, 0600))
+	assert.NoError(t, os.WriteFile(certs.KeyPath, certs.KeyPEM, 0600))
+	return certs
+}
+
+// AddTo adds the certificate files to mfs at their paths, for loaders reading from mfs
+func (c *TestCertificates) AddTo(mfs *MockFileSystem) {
+	mfs.AddFile(c.CAPath, c.CAPEM)
+	mfs.AddFile(c.CertPath, c.CertPEM)
+	mfs.AddFile(c.KeyPath, c.KeyPEM)
+}
@@ -0,0 +1,138 @@
+package secret
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TLS verify modes, full verifies the certificate chain and the server name, ca only the chain
+// and none nothing
+const (
+	VerifyFull = "full"
+	VerifyCA   = "ca"
+	VerifyNone = "none"
+)
+
+// TLS is the TLS material of a connection, CA, Cert and Key each hold either a file path
+// or inline PEM
+type TLS struct {
+	CA         string    `json:"ca"`
+	Cert       string    `json:"cert"`
+	Key        Sensitive `json:"key"`
+	ServerName string    `json:"server_name"`
+	VerifyMode string    `json:"verify_mode" validate:"oneof=full ca none"`
+	// Insecure skips verification like the none verify mode
+	Insecure bool `json:"insecure"`
+}
+
+// isInlinePEM reports whether value holds PEM rather than a file path
+func isInlinePEM(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN")
+}
+
+// isFile reports whether value is a file path rather than inline PEM
+func isFile(value string) bool {
+	return value != "" && !isInlinePEM(value)
+}
+
+func readPEM(fs FileSystemInterface, value string) ([]byte, error) {
+	if isInlinePEM(value) {
+		return []byte(value), nil
+	}
+
+	return fs.ReadFile(value)
+}
+
+// Config returns a client *tls.Config with the material of t applied, serverName is used
+// when t sets no server name
+func (t *TLS) Config(serverName string) (*tls.Config, error) {
+	return t.ConfigFS(&RealFileSystem{}, serverName)
+}
+
+// ConfigFS is Config reading the file paths of t from fs, such as the file system of the
+// Loader the secret was loaded with
+func (t *TLS) ConfigFS(fs FileSystemInterface, serverName string) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: serverName}
+	if t.ServerName != "" {
+		config.ServerName = t.ServerName
+	}
+
+	if t.CA != "" {
+		data, err := readPEM(fs, t.CA)
+		if err != nil {
+			return nil, fmt.Errorf("ca: %w", err)
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("ca: no PEM encoded certificates found")
+		}
+	}
+
+	if t.Cert != "" || t.Key != "" {
+		certificate, err := t.certificate(fs)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	switch {
+	case t.Insecure || t.VerifyMode == VerifyNone:
+		config.InsecureSkipVerify = true
+	case t.VerifyMode == VerifyCA:
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = verifyChain(config.RootCAs)
+	}
+
+	return config, nil
+}
+
+func (t *TLS) certificate(fs FileSystemInterface) (tls.Certificate, error) {
+	if t.Cert == "" || t.Key == "" {
+		return tls.Certificate{}, fmt.Errorf("cert and key should be set together")
+	}
+
+	certPEM, err := readPEM(fs, t.Cert)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("cert: %w", err)
+	}
+
+	keyPEM, err := readPEM(fs, t.Key.Reveal())
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("key: %w", err)
+	}
+
+	if isFile(t.Key.Reveal()) && ownsReads(fs) {
+		defer clear(keyPEM)
+	}
+
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("cert and key: %w", err)
+	}
+
+	return certificate, nil
+}
+
+// verifyChain verifies the peer certificate chain against roots, the system roots when nil,
+// without checking the server name
+func verifyChain(roots *x509.CertPool) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return errors.New("tls: no peer certificate")
+		}
+
+		opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+		for _, intermediate := range state.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(intermediate)
+		}
+
+		_, err := state.PeerCertificates[0].Verify(opts)
+		return err
+	}
+}
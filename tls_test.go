@@ -0,0 +1,133 @@
+package secret
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// handshake runs a TLS handshake between client and a server presenting the test
+// certificate and requiring a client certificate signed by the test CA, returning the
+// client error or else the server one
+func handshake(t *testing.T, certs *TestCertificates, client *tls.Config) error {
+	serverCert, err := tls.X509KeyPair(certs.CertPEM, certs.KeyPEM)
+	assert.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(certs.CAPEM)
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	server := tls.Server(serverConn, &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Handshake()
+		serverConn.Close()
+	}()
+
+	err = tls.Client(clientConn, client).Handshake()
+	clientConn.Close()
+	if serverErr := <-done; err == nil {
+		err = serverErr
+	}
+
+	return err
+}
+
+func TestTLSConfigHandshake(t *testing.T) {
+	t.Parallel()
+	certs := CreateTestCertificates(t, t.TempDir())
+	other := CreateTestCertificates(t, t.TempDir())
+
+	testCases := []struct {
+		name       string
+		tls        TLS
+		serverName string
+		ok         bool
+	}{
+		{"paths", TLS{CA: certs.CAPath, Cert: certs.CertPath, Key: Sensitive(certs.KeyPath)}, "localhost", true},
+		{"inline", TLS{CA: string(certs.CAPEM), Cert: string(certs.CertPEM), Key: Sensitive(certs.KeyPEM)}, "localhost", true},
+		{"wrong server name", TLS{CA: certs.CAPath, Cert: certs.CertPath, Key: Sensitive(certs.KeyPath)}, "db.internal", false},
+		{"verify ca", TLS{CA: certs.CAPath, Cert: certs.CertPath, Key: Sensitive(certs.KeyPath), VerifyMode: VerifyCA}, "db.internal", true},
+		{"verify ca unknown authority", TLS{CA: other.CAPath, Cert: certs.CertPath, Key: Sensitive(certs.KeyPath), VerifyMode: VerifyCA}, "localhost", false},
+		{"verify none", TLS{Cert: certs.CertPath, Key: Sensitive(certs.KeyPath), VerifyMode: VerifyNone}, "db.internal", true},
+		{"server name override", TLS{CA: certs.CAPath, Cert: certs.CertPath, Key: Sensitive(certs.KeyPath), ServerName: "localhost"}, "db.internal", true},
+		{"no client certificate", TLS{CA: certs.CAPath}, "localhost", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := tc.tls.Config(tc.serverName)
+			assert.NoError(t, err)
+			err = handshake(t, certs, config)
+			if tc.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	t.Parallel()
+	certs := CreateTestCertificates(t, t.TempDir())
+	other := CreateTestCertificates(t, t.TempDir())
+
+	_, err := (&TLS{CA: certs.KeyPath}).Config("")
+	assert.EqualError(t, err, "ca: no PEM encoded certificates found")
+
+	_, err = (&TLS{Cert: certs.CertPath}).Config("")
+	assert.EqualError(t, err, "cert and key should be set together")
+
+	_, err = (&TLS{Cert: certs.CertPath, Key: Sensitive(other.KeyPath)}).Config("")
+	assert.EqualError(t, err, "cert and key: tls: private key does not match public key")
+
+	_, err = (&TLS{CA: "/missing/ca.pem"}).Config("")
+	assert.Error(t, err)
+}
+
+func TestDatabaseMetaTLSConfig(t *testing.T) {
+	t.Parallel()
+	certs := CreateTestCertificates(t, t.TempDir())
+	other := CreateTestCertificates(t, t.TempDir())
+	meta := dsnMeta("postgres", "localhost", 5432, "app", "", "")
+
+	config, err := meta.TLSConfig()
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+
+	meta.Params.TLSMode = TLSModeRequire
+	config, err = meta.TLSConfig()
+	assert.NoError(t, err)
+	assert.True(t, config.InsecureSkipVerify)
+
+	meta.Params.TLSMode = TLSModeVerifyFull
+	meta.Params.TLS = TLS{CA: certs.CAPath, Cert: certs.CertPath, Key: Sensitive(certs.KeyPath)}
+	config, err = meta.TLSConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost", config.ServerName)
+	assert.NoError(t, handshake(t, certs, config))
+
+	dsn, err := meta.DSN()
+	assert.NoError(t, err)
+	assert.Contains(t, dsn, "sslcert="+certs.CertPath+" sslkey="+certs.KeyPath+" sslmode=verify-full sslrootcert="+certs.CAPath)
+
+	helper := NewTestHelper()
+	certs.AddTo(helper.GetMockFileSystem())
+	other.AddTo(helper.GetMockFileSystem())
+	helper.GetMockFileSystem().AddFile("database-tls/secret.json", []byte(`{
+		"writer": {"adapter": "mysql", "params": {"host": "db", "tls": {"cert": "`+certs.CertPath+`", "key": "`+other.KeyPath+`"}}}
+	}`))
+	err = helper.MockLoader().Load("database", "tls", &Database{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "writer.params.tls cert and key: tls: private key does not match public key")
+}
@@ -0,0 +1,323 @@
+package secret
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Validator is implemented by secrets that check their own semantics,
+// Load calls Validate after the struct tags passed
+type Validator interface {
+	Validate() error
+}
+
+// FileValidator is implemented by secrets whose checks read files, Load calls ValidateFS
+// with the file system of the loader in place of Validate
+type FileValidator interface {
+	ValidateFS(fs FileSystemInterface) error
+}
+
+// FieldError describes one field of a secret that failed validation
+type FieldError struct {
+	Path    string
+	Rule    string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Path, e.Message)
+}
+
+// ValidationError aggregates every invalid field of a loaded secret
+type ValidationError struct {
+	Fields []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Error())
+	}
+
+	return fmt.Sprintf("secret validation failed: %s", strings.Join(messages, "; "))
+}
+
+// Add records an invalid field
+func (e *ValidationError) Add(path string, rule string, format string, args ...interface{}) {
+	e.Fields = append(e.Fields, &FieldError{Path: path, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err returns e when it holds any field error and nil otherwise
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// validateSecret checks the `validate` struct tags of v, rules are comma separated:
+//
+//	required     the value is not zero
+//	min=N max=N  numbers are within range, strings and slices have a length within range
+//	oneof=a b c  the value is one of the space separated options
+//	hostname     the value is a RFC 1123 hostname or an IP address
+//	url          the value is an absolute URL
+//	file         the value names an existing file
+//
+// Zero values only fail required, and zero structs are skipped unless required,
+// which keeps optional sections like Reader unchecked when they are absent.
+// Once the tags pass, secrets implementing FileValidator or Validator are asked to
+// validate themselves
+func validateSecret(v reflect.Value, fs FileSystemInterface) error {
+	errs := &ValidationError{}
+	validateStruct(v, "", fs, errs)
+	if len(errs.Fields) > 0 {
+		return errs
+	}
+
+	if validator, ok := v.Addr().Interface().(FileValidator); ok {
+		return validator.ValidateFS(fs)
+	}
+
+	validator, ok := v.Addr().Interface().(Validator)
+	if !ok {
+		return nil
+	}
+
+	return validator.Validate()
+}
+
+func validateStruct(v reflect.Value, prefix string, fs FileSystemInterface, errs *ValidationError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldValue := v.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			validateStruct(fieldValue, prefix, fs, errs)
+			continue
+		}
+
+		fieldPath := joinFieldPath(prefix, fieldName(field))
+		validateField(fieldValue, fieldPath, parseRules(field.Tag.Get("validate")), fs, errs)
+	}
+}
+
+func validateField(v reflect.Value, fieldPath string, rules []rule, fs FileSystemInterface, errs *ValidationError) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if hasRule(rules, "required") {
+				errs.Add(fieldPath, "required", "is required")
+			}
+
+			return
+		}
+
+		v = v.Elem()
+	}
+
+	if v.IsZero() {
+		if hasRule(rules, "required") {
+			errs.Add(fieldPath, "required", "is required")
+		}
+
+		return
+	}
+
+	for _, r := range rules {
+		applyRule(v, fieldPath, r, fs, errs)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		validateStruct(v, fieldPath, fs, errs)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Struct {
+			for i := 0; i < v.Len(); i++ {
+				validateStruct(v.Index(i), fmt.Sprintf("%s[%d]", fieldPath, i), fs, errs)
+			}
+		}
+	}
+}
+
+type rule struct {
+	name  string
+	param string
+}
+
+func parseRules(tag string) []rule {
+	var rules []rule
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, param, _ := strings.Cut(part, "=")
+		rules = append(rules, rule{name: name, param: param})
+	}
+
+	return rules
+}
+
+func hasRule(rules []rule, name string) bool {
+	for _, r := range rules {
+		if r.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func applyRule(v reflect.Value, fieldPath string, r rule, fs FileSystemInterface, errs *ValidationError) {
+	switch r.name {
+	case "required":
+	case "min", "max":
+		limit, err := strconv.ParseFloat(r.param, 64)
+		if err != nil {
+			errs.Add(fieldPath, r.name, "has invalid %s rule %q", r.name, r.param)
+			return
+		}
+
+		n, unit, ok := measure(v)
+		if !ok {
+			return
+		}
+
+		if r.name == "min" && n < limit {
+			errs.Add(fieldPath, r.name, "must be at least %s%s", r.param, unit)
+		} else if r.name == "max" && n > limit {
+			errs.Add(fieldPath, r.name, "must be at most %s%s", r.param, unit)
+		}
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		for _, option := range strings.Fields(r.param) {
+			if s == option {
+				return
+			}
+		}
+
+		errs.Add(fieldPath, r.name, "must be one of [%s], got %q", r.param, s)
+	case "hostname":
+		if s, ok := stringOf(v); ok && !isHostname(s) {
+			errs.Add(fieldPath, r.name, "must be a valid hostname, got %q", s)
+		}
+	case "url":
+		if s, ok := stringOf(v); ok {
+			if u, err := url.Parse(s); err != nil || u.Scheme == "" {
+				errs.Add(fieldPath, r.name, "must be an absolute URL, got %q", s)
+			}
+		}
+	case "file":
+		if s, ok := stringOf(v); ok {
+			if info, err := fs.Stat(s); err != nil {
+				errs.Add(fieldPath, r.name, "must be an existing file: %v", err)
+			} else if info.IsDir() {
+				errs.Add(fieldPath, r.name, "must be a file, %q is a directory", s)
+			}
+		}
+	default:
+		errs.Add(fieldPath, r.name, "has unknown validation rule %q", r.name)
+	}
+}
+
+func measure(v reflect.Value) (float64, string, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "", true
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), " in length", true
+	}
+
+	return 0, "", false
+}
+
+func stringOf(v reflect.Value) (string, bool) {
+	if v.Kind() != reflect.String {
+		return "", false
+	}
+
+	return v.String(), true
+}
+
+func isHostname(s string) bool {
+	if net.ParseIP(strings.Trim(s, "[]")) != nil {
+		return true
+	}
+
+	if len(s) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(strings.TrimSuffix(s, "."), ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// fieldName returns the json name of field, falling back to the Go field name
+func fieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+
+	return field.Name
+}
+
+func joinFieldPath(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + "." + name
+}
+
+// checkPEMCertificates checks file holds at least one PEM encoded certificate
+func checkPEMCertificates(fs FileSystemInterface, file string) error {
+	data, err := fs.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("must be a readable file: %v", err)
+	}
+
+	if !x509.NewCertPool().AppendCertsFromPEM(data) {
+		return fmt.Errorf("must hold PEM encoded certificates, %s has none", file)
+	}
+
+	return nil
+}
+
+// validateHostPort checks addr is a host:port pair with a port within 1-65535
+func validateHostPort(fieldPath string, addr string, errs *ValidationError) {
+	if _, port, err := net.SplitHostPort(addr); err != nil {
+		errs.Add(fieldPath, "hostport", "must be host:port, got %q", addr)
+	} else if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		errs.Add(fieldPath, "hostport", "must have a port within 1-65535, got %q", port)
+	}
+}
+
+func isZero(v interface{}) bool {
+	return reflect.ValueOf(v).IsZero()
+}
@@ -0,0 +1,187 @@
+package secret
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type validatedSecret struct {
+	DefaultSecret
+	Mode     string   `json:"mode" validate:"oneof=fast safe"`
+	Endpoint string   `json:"endpoint" validate:"url"`
+	Hosts    []string `json:"hosts" validate:"min=1,max=2"`
+	Items    []struct {
+		Name string `json:"name" validate:"required"`
+	} `json:"items"`
+	Optional struct {
+		Host string `json:"host" validate:"required"`
+	} `json:"optional"`
+}
+
+func TestValidationAggregatesFieldPaths(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	helper.GetMockFileSystem().AddFile("redis-invalid/secret.json", []byte(`{
+		"master": {"host": "bad host!", "port": 70000},
+		"slave": {"host": "-reader", "db": 1}
+	}`))
+
+	err := helper.MockLoader().Load("redis", "invalid", &Redis{})
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+
+	paths := make([]string, 0)
+	for _, field := range validationErr.Fields {
+		paths = append(paths, field.Path+":"+field.Rule)
+	}
+
+	assert.Equal(t, []string{
+		"master.host:hostname",
+		"master.port:max",
+		"slave.host:hostname",
+	}, paths)
+	assert.Contains(t, err.Error(), `slave.host must be a valid hostname, got "-reader"`)
+}
+
+func TestValidationSkipsAbsentOptionalSections(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	helper.GetMockFileSystem().AddFile("redis-valid/secret.json", []byte(`{"master": {"host": "10.0.0.1", "port": 6379}}`))
+
+	assert.NoError(t, helper.MockLoader().Load("redis", "valid", &Redis{}))
+}
+
+func TestValidationFileRule(t *testing.T) {
+	t.Parallel()
+	certs := CreateTestCertificates(t, t.TempDir())
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("ca.pem", certs.CAPEM)
+	mockFS.AddFile("cert.pem", certs.CertPEM)
+	mockFS.AddFile("key.pem", certs.KeyPEM)
+	mockFS.AddFile("cassandra-ca/secret.json", []byte(`{"writer": {"endpoints": ["h1:9042"], "ca_path": "ca.pem", "cert_path": "cert.pem", "key_path": "key.pem"}}`))
+	mockFS.AddFile("cassandra-noca/secret.json", []byte(`{"writer": {"endpoints": ["h1:9042"], "ca_path": "missing.pem"}}`))
+
+	c := &Cassandra{}
+	assert.NoError(t, helper.MockLoader().Load("cassandra", "ca", c))
+	config, err := c.Writer.TLSConfigFS(mockFS)
+	assert.NoError(t, err)
+	assert.NotNil(t, config.RootCAs)
+	assert.Len(t, config.Certificates, 1)
+
+	err = helper.MockLoader().Load("cassandra", "noca", &Cassandra{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "writer.ca_path must be an existing file")
+}
+
+func TestDatabaseValidate(t *testing.T) {
+	t.Parallel()
+	db := CreateDatabaseSecret("validate")
+	assert.NoError(t, db.Validate())
+
+	db.Writer.Adapter = "oracle"
+	db.Reader = DatabaseMeta{}
+	assert.EqualError(t, db.Validate(), `secret validation failed: writer.adapter must be a known adapter, got "oracle"`)
+
+	RegisterDatabaseAdapter("oracle")
+	defer func() {
+		databaseAdaptersMu.Lock()
+		delete(databaseAdapters, "oracle")
+		databaseAdaptersMu.Unlock()
+	}()
+	assert.NoError(t, db.Validate())
+
+	db.Reader.Adapter = "mysql"
+	assert.EqualError(t, db.Validate(), "secret validation failed: reader.params.host is required; reader.params.port must be within 1-65535, got 0")
+
+	db.Reader.Adapter = "sqlite"
+	assert.EqualError(t, db.Validate(), "secret validation failed: reader.params.dbname is required by the sqlite adapter")
+
+	db.Reader.Params.DBName = "/var/lib/app.db"
+	assert.NoError(t, db.Validate())
+}
+
+func TestDatabasePostgresSocketHost(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("database-socket/secret.json", []byte(`{"writer": {"adapter": "postgres", "params": {"host": "/var/run/postgresql", "dbname": "app"}}}`))
+	mockFS.AddFile("database-mysqlsocket/secret.json", []byte(`{"writer": {"adapter": "mysql", "params": {"host": "/var/run/mysqld"}}}`))
+
+	db := &Database{}
+	assert.NoError(t, helper.MockLoader().Load("database", "socket", db))
+	dsn, err := db.Writer.DSN()
+	assert.NoError(t, err)
+	assert.Equal(t, "host=/var/run/postgresql port=5432 dbname=app", dsn)
+	u, err := db.Writer.URL()
+	assert.NoError(t, err)
+	assert.Equal(t, "postgres:///app?host=%2Fvar%2Frun%2Fpostgresql&port=5432", u)
+
+	err = helper.MockLoader().Load("database", "mysqlsocket", &Database{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `writer.params.host must be a valid hostname, got "/var/run/mysqld"`)
+}
+
+func TestRedisValidate(t *testing.T) {
+	t.Parallel()
+	redis := CreateRedisSecret("validate")
+	assert.NoError(t, redis.Validate())
+
+	redis.Slave = RedisMeta{}
+	assert.NoError(t, redis.Validate())
+
+	redis.Slave.Port = 6380
+	redis.Master = RedisMeta{}
+	assert.EqualError(t, redis.Validate(), "secret validation failed: master must set host and port; slave must set host and port when present")
+}
+
+func TestCassandraValidate(t *testing.T) {
+	t.Parallel()
+	certs := CreateTestCertificates(t, t.TempDir())
+	c := CreateCassandraSecret("validate")
+	c.Writer.CaPath = certs.CAPath
+	assert.NoError(t, c.Validate())
+
+	c.Writer.Endpoints = []string{"localhost", "localhost:0"}
+	c.Writer.CaPath = certs.KeyPath
+	c.Reader = CassandraMeta{}
+	err := c.Validate()
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Len(t, validationErr.Fields, 3)
+	assert.Equal(t, "writer.endpoints[0]", validationErr.Fields[0].Path)
+	assert.Equal(t, "writer.endpoints[1]", validationErr.Fields[1].Path)
+	assert.Equal(t, "writer.ca_path", validationErr.Fields[2].Path)
+	assert.Contains(t, err.Error(), "must hold PEM encoded certificates")
+}
+
+func TestValidationRules(t *testing.T) {
+	t.Parallel()
+	helper := NewTestHelper()
+	mockFS := helper.GetMockFileSystem()
+	mockFS.AddFile("custom-invalid/secret.json", []byte(`{
+		"mode": "slow",
+		"endpoint": "/relative",
+		"hosts": ["a", "b", "c"],
+		"items": [{"name": "one"}, {}]
+	}`))
+	mockFS.AddFile("custom-valid/secret.json", []byte(`{
+		"mode": "safe",
+		"endpoint": "https://example.com",
+		"hosts": ["a"],
+		"optional": {"host": "h"}
+	}`))
+
+	err := helper.MockLoader().Load("custom", "invalid", &validatedSecret{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `mode must be one of [fast safe], got "slow"`)
+	assert.Contains(t, err.Error(), "endpoint must be an absolute URL")
+	assert.Contains(t, err.Error(), "hosts must be at most 2 in length")
+	assert.Contains(t, err.Error(), "items[1].name is required")
+	assert.NotContains(t, err.Error(), "optional")
+
+	assert.NoError(t, helper.MockLoader().Load("custom", "valid", &validatedSecret{}))
+}